@@ -0,0 +1,205 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// completionShells are the shells runCompletion can generate scripts for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// subcommand describes a first-argument subcommand for completion
+type subcommand struct {
+	name  string
+	usage string
+	flags *flag.FlagSet // nil when the subcommand takes no flags
+	args  []string      // fixed positional values, if any
+}
+
+// subcommands lists the subcommands with flag sets built by the same functions run uses
+func subcommands() []subcommand {
+	inspectFlags, _ := newInspectFlagSet("inspect", io.Discard)
+	return []subcommand{
+		{name: "inspect", usage: "Report the structure of a wrapped block as JSON", flags: inspectFlags},
+		{name: "completion", usage: "Print a shell completion script", args: completionShells},
+	}
+}
+
+// completionFlag is one flag as a completion script needs it
+type completionFlag struct {
+	name     string
+	usage    string
+	takesArg bool
+}
+
+// flagsOf lists the flags of fs in name order; a nil flag set has none
+func flagsOf(fs *flag.FlagSet) []completionFlag {
+	if fs == nil {
+		return nil
+	}
+	var flags []completionFlag
+	fs.VisitAll(func(f *flag.Flag) {
+		isBool := false
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok {
+			isBool = b.IsBoolFlag()
+		}
+		usage, _, _ := strings.Cut(f.Usage, "\n")
+		flags = append(flags, completionFlag{name: f.Name, usage: usage, takesArg: !isBool})
+	})
+	return flags
+}
+
+// runCompletion prints a completion script for the shell named in args[1]
+func runCompletion(args []string, stdout io.Writer) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: prompt-sanitizer completion %s", strings.Join(completionShells, "|"))
+	}
+	top, _ := newFlagSet("prompt-sanitizer", io.Discard)
+	topFlags := flagsOf(top)
+	subs := subcommands()
+
+	var script string
+	switch args[1] {
+	case "bash":
+		script = bashCompletion(topFlags, subs)
+	case "zsh":
+		script = zshCompletion(topFlags, subs)
+	case "fish":
+		script = fishCompletion(topFlags, subs)
+	default:
+		return fmt.Errorf("unsupported shell %q: want %s", args[1], strings.Join(completionShells, ", "))
+	}
+	_, err := io.WriteString(stdout, script)
+	return err
+}
+
+// flagWords returns the flags as --name words
+func flagWords(flags []completionFlag) []string {
+	words := make([]string, len(flags))
+	for i, f := range flags {
+		words[i] = "--" + f.name
+	}
+	return words
+}
+
+func bashCompletion(topFlags []completionFlag, subs []subcommand) string {
+	var b strings.Builder
+	top := flagWords(topFlags)
+	b.WriteString("# bash completion for prompt-sanitizer\n")
+	b.WriteString("_prompt_sanitizer() {\n")
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	b.WriteString("    local words\n")
+	b.WriteString("    if [[ $COMP_CWORD -eq 1 ]]; then\n")
+	names := make([]string, len(subs))
+	for i, sub := range subs {
+		names[i] = sub.name
+	}
+	fmt.Fprintf(&b, "        words=%q\n", strings.Join(append(top, names...), " "))
+	b.WriteString("    else\n")
+	b.WriteString("        case \"${COMP_WORDS[1]}\" in\n")
+	for _, sub := range subs {
+		words := append(flagWords(flagsOf(sub.flags)), sub.args...)
+		fmt.Fprintf(&b, "            %s) words=%q ;;\n", sub.name, strings.Join(words, " "))
+	}
+	fmt.Fprintf(&b, "            *) words=%q ;;\n", strings.Join(top, " "))
+	b.WriteString("        esac\n")
+	b.WriteString("    fi\n")
+	b.WriteString("    COMPREPLY=($(compgen -W \"$words\" -- \"$cur\"))\n")
+	b.WriteString("}\n")
+	b.WriteString("complete -o default -F _prompt_sanitizer prompt-sanitizer\n")
+	return b.String()
+}
+
+// zshQuote quotes s as a single-quoted zsh word
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// zshDescribed renders name:description entries for _describe, escaping colons in names
+func zshDescribed(names, usages []string) string {
+	entries := make([]string, len(names))
+	for i := range names {
+		entries[i] = zshQuote(strings.ReplaceAll(names[i], ":", `\:`) + ":" + usages[i])
+	}
+	return strings.Join(entries, " ")
+}
+
+func zshCompletion(topFlags []completionFlag, subs []subcommand) string {
+	describedFlags := func(flags []completionFlag) string {
+		names := make([]string, len(flags))
+		usages := make([]string, len(flags))
+		for i, f := range flags {
+			names[i], usages[i] = "--"+f.name, f.usage
+		}
+		return zshDescribed(names, usages)
+	}
+
+	var b strings.Builder
+	b.WriteString("#compdef prompt-sanitizer\n\n")
+	b.WriteString("_prompt_sanitizer() {\n")
+	b.WriteString("    local -a opts\n")
+	b.WriteString("    if (( CURRENT == 2 )); then\n")
+	subNames := make([]string, len(subs))
+	subUsages := make([]string, len(subs))
+	for i, sub := range subs {
+		subNames[i], subUsages[i] = sub.name, sub.usage
+	}
+	fmt.Fprintf(&b, "        opts=(%s %s)\n", describedFlags(topFlags), zshDescribed(subNames, subUsages))
+	b.WriteString("    else\n")
+	b.WriteString("        case $words[2] in\n")
+	for _, sub := range subs {
+		entries := describedFlags(flagsOf(sub.flags))
+		if len(sub.args) > 0 {
+			entries = strings.TrimSpace(entries + " " + zshDescribed(sub.args, sub.args))
+		}
+		fmt.Fprintf(&b, "            %s) opts=(%s) ;;\n", sub.name, entries)
+	}
+	fmt.Fprintf(&b, "            *) opts=(%s) ;;\n", describedFlags(topFlags))
+	b.WriteString("        esac\n")
+	b.WriteString("    fi\n")
+	b.WriteString("    _describe 'prompt-sanitizer' opts\n")
+	b.WriteString("    _files\n")
+	b.WriteString("}\n\n")
+	b.WriteString("compdef _prompt_sanitizer prompt-sanitizer\n")
+	return b.String()
+}
+
+// fishQuote quotes s as a single-quoted fish word
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+func fishCompletion(topFlags []completionFlag, subs []subcommand) string {
+	var b strings.Builder
+	names := make([]string, len(subs))
+	for i, sub := range subs {
+		names[i] = sub.name
+	}
+	notSub := fishQuote("not __fish_seen_subcommand_from " + strings.Join(names, " "))
+
+	writeFlags := func(condition string, flags []completionFlag) {
+		for _, f := range flags {
+			fmt.Fprintf(&b, "complete -c prompt-sanitizer -n %s -l %s -d %s", condition, f.name, fishQuote(f.usage))
+			if f.takesArg {
+				b.WriteString(" -r")
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("# fish completion for prompt-sanitizer\n")
+	for _, sub := range subs {
+		fmt.Fprintf(&b, "complete -c prompt-sanitizer -f -n %s -a %s -d %s\n", fishQuote("__fish_use_subcommand"), sub.name, fishQuote(sub.usage))
+	}
+	writeFlags(notSub, topFlags)
+	for _, sub := range subs {
+		seen := fishQuote("__fish_seen_subcommand_from " + sub.name)
+		writeFlags(seen, flagsOf(sub.flags))
+		if len(sub.args) > 0 {
+			fmt.Fprintf(&b, "complete -c prompt-sanitizer -f -n %s -a %s\n", seen, fishQuote(strings.Join(sub.args, " ")))
+		}
+	}
+	return b.String()
+}
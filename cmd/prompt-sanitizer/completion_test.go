@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompletion_ListsEveryFlag(t *testing.T) {
+	top, _ := newFlagSet("prompt-sanitizer", io.Discard)
+	inspect, _ := newInspectFlagSet("inspect", io.Discard)
+
+	for _, shell := range completionShells {
+		t.Run(shell, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			if err := run([]string{"prompt-sanitizer", "completion", shell}, &bytes.Buffer{}, stdout, &bytes.Buffer{}); err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			script := stdout.String()
+
+			// Flags are derived from the flag sets, so every defined flag must appear
+			for _, fs := range []*flag.FlagSet{top, inspect} {
+				fs.VisitAll(func(f *flag.Flag) {
+					name := "--" + f.Name
+					if shell == "fish" {
+						name = "-l " + f.Name
+					}
+					if !strings.Contains(script, name) {
+						t.Errorf("%s completion missing flag %s", shell, f.Name)
+					}
+				})
+			}
+			for _, word := range []string{"inspect", "completion", "bash", "zsh", "fish"} {
+				if !strings.Contains(script, word) {
+					t.Errorf("%s completion missing %q", shell, word)
+				}
+			}
+		})
+	}
+}
+
+func TestCompletion_Syntax(t *testing.T) {
+	for _, shell := range completionShells {
+		t.Run(shell, func(t *testing.T) {
+			path, err := exec.LookPath(shell)
+			if err != nil {
+				t.Skipf("%s not installed", shell)
+			}
+			stdout := &bytes.Buffer{}
+			if err := run([]string{"prompt-sanitizer", "completion", shell}, &bytes.Buffer{}, stdout, &bytes.Buffer{}); err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			script := filepath.Join(t.TempDir(), "completion."+shell)
+			if err := os.WriteFile(script, stdout.Bytes(), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if out, err := exec.Command(path, "-n", script).CombinedOutput(); err != nil {
+				t.Errorf("%s -n failed: %v\n%s", shell, err, out)
+			}
+		})
+	}
+}
+
+func TestCompletion_BashCompletesFlags(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+	stdout := &bytes.Buffer{}
+	if err := run([]string{"prompt-sanitizer", "completion", "bash"}, &bytes.Buffer{}, stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	script := stdout.String() + `
+COMP_WORDS=(prompt-sanitizer --leg); COMP_CWORD=1; _prompt_sanitizer; echo "${COMPREPLY[*]}"
+COMP_WORDS=(prompt-sanitizer completion z); COMP_CWORD=2; _prompt_sanitizer; echo "${COMPREPLY[*]}"
+`
+	out, err := exec.Command("bash", "-c", script).CombinedOutput()
+	if err != nil {
+		t.Fatalf("bash error = %v\n%s", err, out)
+	}
+	if got, want := string(out), "--legend --legend-text\nzsh\n"; got != want {
+		t.Errorf("completions = %q, want %q", got, want)
+	}
+}
+
+func TestCompletion_Errors(t *testing.T) {
+	for _, args := range [][]string{{"completion"}, {"completion", "powershell"}, {"completion", "bash", "extra"}} {
+		err := run(append([]string{"prompt-sanitizer"}, args...), &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{})
+		if err == nil {
+			t.Errorf("run(%q) expected error", args)
+		}
+	}
+}
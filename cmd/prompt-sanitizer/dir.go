@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// collectDir reads every regular file under root whose extension is in exts (any file when
+// exts is empty) as an input labelled with its slash-separated path relative to root, in
+// lexical order. Symlinks are skipped rather than followed, so a link can't pull in files
+// from outside the tree. A file or subdirectory that can't be read is reported on stderr and
+// skipped; only a root that can't be walked is an error.
+func collectDir(root string, exts []string, stderr io.Writer) ([]input, error) {
+	var inputs []input
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			fmt.Fprintf(stderr, "warning: skipping %s: %v\n", path, err)
+			return nil
+		}
+		if !d.Type().IsRegular() || !hasExt(path, exts) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "warning: skipping %s: %v\n", path, err)
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		inputs = append(inputs, input{content: string(data), source: filepath.ToSlash(rel)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inputs, nil
+}
+
+// readFiles0 reads a NUL-separated list of paths, as printed by find -print0, from the named
+// file or, for "-", from stdin. Empty entries, such as the one after a trailing NUL, are
+// dropped.
+func readFiles0(name string, stdin io.Reader) ([]string, error) {
+	list := stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		list = f
+	}
+	data, err := io.ReadAll(list)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, path := range strings.Split(string(data), "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// collectFiles0 reads each path from a --files0-from list as an input labelled with its path
+// as listed. A file that can't be read is reported on stderr and skipped.
+func collectFiles0(paths []string, stderr io.Writer) []input {
+	var inputs []input
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "warning: skipping %s: %v\n", path, err)
+			continue
+		}
+		inputs = append(inputs, input{content: string(content), source: path})
+	}
+	return inputs
+}
+
+// parseExts splits a --ext list such as ".txt,md" into extensions with a leading dot
+func parseExts(spec string) []string {
+	var exts []string
+	for _, ext := range strings.Split(spec, ",") {
+		ext = strings.TrimSpace(ext)
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		exts = append(exts, ext)
+	}
+	return exts
+}
+
+// hasExt reports whether path ends in one of exts, ignoring case, or exts is empty
+func hasExt(path string, exts []string) bool {
+	if len(exts) == 0 {
+		return true
+	}
+	ext := filepath.Ext(path)
+	for _, want := range exts {
+		if strings.EqualFold(ext, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandGlobs replaces each --file argument containing glob metacharacters with the
+// files it matches, in lexical order, skipping directories. An argument naming an existing
+// file is kept as is even if it contains metacharacters. A pattern matching no files is an
+// error rather than silently wrapping nothing. globbed reports whether any pattern was
+// expanded.
+func expandGlobs(paths []string) (expanded []string, globbed bool, err error) {
+	for _, path := range paths {
+		if !strings.ContainsAny(path, "*?[") {
+			expanded = append(expanded, path)
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			expanded = append(expanded, path)
+			continue
+		}
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid --file pattern %q: %w", path, err)
+		}
+		n := len(expanded)
+		for _, m := range matches {
+			if info, err := os.Stat(m); err == nil && info.IsDir() {
+				continue
+			}
+			expanded = append(expanded, m)
+		}
+		if len(expanded) == n {
+			return nil, false, fmt.Errorf("--file pattern %q matches no files", path)
+		}
+		globbed = true
+	}
+	return expanded, globbed, nil
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openclaw/prompt-sanitizer/pkg/wrapper"
+)
+
+// writeTree creates files under dir from a map of slash-separated relative paths to contents
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDirMode(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"a.txt":         "alpha",
+		"b.md":          "bravo",
+		"image.png":     "\x89PNG",
+		"sub/c.TXT":     "charlie",
+		"sub/deep/d.md": "delta",
+	})
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	writeTree(t, filepath.Dir(outside), map[string]string{"secret.txt": "secret"})
+	if err := os.Symlink(outside, filepath.Join(dir, "link.txt")); err != nil {
+		t.Logf("Symlinks unavailable: %v", err)
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	args := []string{"prompt-sanitizer", "--dir", dir, "--ext", ".txt,md"}
+	if err := run(args, &bytes.Buffer{}, stdout, stderr); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		wrapper.WrapContent("alpha", "a.txt"),
+		wrapper.WrapContent("bravo", "b.md"),
+		wrapper.WrapContent("charlie", "sub/c.TXT"),
+		wrapper.WrapContent("delta", "sub/deep/d.md"),
+	}, "\n\n") + "\n"
+	if stdout.String() != want {
+		t.Errorf("output = %q, want %q", stdout.String(), want)
+	}
+	if strings.Contains(stdout.String(), "secret") {
+		t.Error("Symlink was followed")
+	}
+
+	// Without --ext every regular file is wrapped
+	stdout.Reset()
+	if err := run([]string{"prompt-sanitizer", "--dir", dir}, &bytes.Buffer{}, stdout, stderr); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if n := strings.Count(stdout.String(), "<<<EXTERNAL_UNTRUSTED_CONTENT>>>"); n != 5 {
+		t.Errorf("Got %d blocks without --ext, want 5", n)
+	}
+}
+
+func TestDirMode_UnreadableFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"ok.txt": "fine", "locked.txt": "hidden"})
+	locked := filepath.Join(dir, "locked.txt")
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.ReadFile(locked); err == nil {
+		t.Skip("Permissions are not enforced for this user")
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	if err := run([]string{"prompt-sanitizer", "--dir", dir}, &bytes.Buffer{}, stdout, stderr); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if stdout.String() != wrapper.WrapContent("fine", "ok.txt")+"\n" {
+		t.Errorf("output = %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "warning: skipping") || !strings.Contains(stderr.String(), "locked.txt") {
+		t.Errorf("stderr = %q, want a warning about locked.txt", stderr.String())
+	}
+}
+
+func TestDirMode_Errors(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"a.txt": "alpha"})
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"missing directory", []string{"--dir", filepath.Join(dir, "missing")}, "reading directory"},
+		{"no matching files", []string{"--dir", dir, "--ext", "md"}, "no files to wrap"},
+		{"ext without dir", []string{"--ext", "txt"}, "--ext only applies to --dir"},
+		{"dir with file", []string{"--dir", dir, "--file", filepath.Join(dir, "a.txt")}, "cannot be combined"},
+		{"dir with command", []string{"--dir", dir, "--", "echo"}, "cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"prompt-sanitizer"}, tt.args...)
+			err := run(args, strings.NewReader("x"), &bytes.Buffer{}, &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("run() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileGlob(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"logs/a.txt":     "alpha",
+		"logs/b.txt":     "beta",
+		"logs/c.md":      "not matched",
+		"logs/sub.txt/x": "a directory matching the pattern",
+		"other/d.txt":    "not matched either",
+		"literal[1].txt": "exact name",
+	})
+
+	stdout := &bytes.Buffer{}
+	pattern := filepath.Join(dir, "logs", "*.txt")
+	if err := run([]string{"prompt-sanitizer", "--file", pattern}, nil, stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	a, b := filepath.Join(dir, "logs", "a.txt"), filepath.Join(dir, "logs", "b.txt")
+	if want := wrapper.WrapContent("alpha", a) + "\n\n" + wrapper.WrapContent("beta", b) + "\n"; stdout.String() != want {
+		t.Errorf("output = %q, want %q", stdout.String(), want)
+	}
+
+	// A single match is still labelled with its path; an existing name is taken literally
+	// and wrapped like any single --file
+	for _, tt := range []struct{ arg, content, source string }{
+		{filepath.Join(dir, "logs", "a.t?t"), "alpha", a},
+		{filepath.Join(dir, "literal[1].txt"), "exact name", "Unknown"},
+	} {
+		stdout.Reset()
+		if err := run([]string{"prompt-sanitizer", "--file", tt.arg}, nil, stdout, &bytes.Buffer{}); err != nil {
+			t.Fatalf("run(%q) error = %v", tt.arg, err)
+		}
+		if want := wrapper.WrapContent(tt.content, tt.source) + "\n"; stdout.String() != want {
+			t.Errorf("run(%q) = %q, want %q", tt.arg, stdout.String(), want)
+		}
+	}
+
+	for _, tt := range []struct{ arg, wantErr string }{
+		{filepath.Join(dir, "logs", "*.json"), "matches no files"},
+		{filepath.Join(dir, "o*"), "matches no files"},
+		{filepath.Join(dir, "logs", "[.txt"), "invalid --file pattern"},
+	} {
+		err := run([]string{"prompt-sanitizer", "--file", tt.arg}, nil, &bytes.Buffer{}, &bytes.Buffer{})
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("run(%q) error = %v, want %q", tt.arg, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFiles0From(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"a.txt": "alpha", "with space.txt": "bravo"})
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "with space.txt")
+	missing := filepath.Join(dir, "missing.txt")
+
+	tests := []struct {
+		name     string
+		args     []string
+		list     string
+		want     string
+		wantWarn string
+	}{
+		{"two paths", nil, a + "\x00" + b + "\x00", wrapper.WrapContent("alpha", a) + "\n\n" + wrapper.WrapContent("bravo", b) + "\n", ""},
+		{"no trailing NUL", nil, a, wrapper.WrapContent("alpha", a) + "\n", ""},
+		{"source given", []string{"--source", "repo"}, a + "\x00" + b, wrapper.WrapContent("alpha", "repo") + "\n\n" + wrapper.WrapContent("bravo", "repo") + "\n", ""},
+		{"unreadable skipped", nil, missing + "\x00" + a + "\x00" + dir, wrapper.WrapContent("alpha", a) + "\n", "warning: skipping " + missing},
+		{"empty list", nil, "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			args := append([]string{"prompt-sanitizer", "--files0-from", "-"}, tt.args...)
+			if err := run(args, strings.NewReader(tt.list), stdout, stderr); err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if stdout.String() != tt.want {
+				t.Errorf("output = %q, want %q", stdout.String(), tt.want)
+			}
+			if tt.wantWarn == "" && stderr.Len() != 0 || !strings.Contains(stderr.String(), tt.wantWarn) {
+				t.Errorf("stderr = %q, want %q", stderr.String(), tt.wantWarn)
+			}
+		})
+	}
+
+	// The list can come from a file as well
+	listPath := filepath.Join(t.TempDir(), "list")
+	if err := os.WriteFile(listPath, []byte(b+"\x00"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout := &bytes.Buffer{}
+	if err := run([]string{"prompt-sanitizer", "--files0-from", listPath}, &bytes.Buffer{}, stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if want := wrapper.WrapContent("bravo", b) + "\n"; stdout.String() != want {
+		t.Errorf("output = %q, want %q", stdout.String(), want)
+	}
+
+	if err := run([]string{"prompt-sanitizer", "--files0-from", "-", "--file", a}, &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Error("run() accepted --files0-from with --file")
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/openclaw/prompt-sanitizer/pkg/wrapper"
+)
+
+// gitFilter runs one git clean or smudge pass over stdin. Clean wraps the bytes and appends a
+// newline; smudge returns the content of a block written by clean. Bytes go through untouched,
+// so binary files round-trip exactly. Smudge also decodes blocks written with --base64.
+// Smudge passes input that is not a single block, or doesn't decode, through unchanged, so
+// files committed before the filter was configured still check out. With strict set, smudge
+// instead fails on anything UnwrapStrict rejects, including non-blocks.
+func gitFilter(mode, source string, strict bool, stdin io.Reader, stdout io.Writer) error {
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return fmt.Errorf("reading stdin: %w", err)
+	}
+
+	var out string
+	switch mode {
+	case "clean":
+		out = wrapper.WrapContent(string(data), source) + "\n"
+	case "smudge":
+		out = string(data)
+		parse := wrapper.ParseBlock
+		if strict {
+			parse = wrapper.UnwrapStrict
+		}
+		b, err := parse(strings.TrimSuffix(out, "\n"))
+		var content []byte
+		if err == nil {
+			content, err = b.DecodedContent()
+		}
+		if err == nil {
+			out = string(content)
+		} else if strict {
+			return fmt.Errorf("strict unwrap: %w", err)
+		}
+	default:
+		return fmt.Errorf("invalid --git-filter %q: want clean or smudge", mode)
+	}
+
+	_, err = io.WriteString(stdout, out)
+	return err
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/openclaw/prompt-sanitizer/pkg/wrapper"
+)
+
+func TestGitFilter_RoundTrip(t *testing.T) {
+	allBytes := make([]byte, 256)
+	for i := range allBytes {
+		allBytes[i] = byte(i)
+	}
+
+	inputs := map[string]string{
+		"text":              "hello\nworld\n",
+		"no final newline":  "hello",
+		"empty":             "",
+		"crlf":              "a\r\nb\r\n",
+		"all byte values":   string(allBytes),
+		"png header":        "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+		"embedded markers":  wrapper.WrapContent("inner", "x") + "\n",
+		"trailing newlines": "a\n\n\n",
+	}
+
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			cleaned := &bytes.Buffer{}
+			if err := run([]string{"prompt-sanitizer", "--git-filter", "clean", "--source", "repo/file.txt"}, strings.NewReader(input), cleaned, &bytes.Buffer{}); err != nil {
+				t.Fatalf("clean error = %v", err)
+			}
+			if want := wrapper.WrapContent(input, "repo/file.txt") + "\n"; cleaned.String() != want {
+				t.Errorf("clean output = %q, want %q", cleaned.String(), want)
+			}
+
+			smudged := &bytes.Buffer{}
+			if err := run([]string{"prompt-sanitizer", "--git-filter", "smudge"}, bytes.NewReader(cleaned.Bytes()), smudged, &bytes.Buffer{}); err != nil {
+				t.Fatalf("smudge error = %v", err)
+			}
+			if smudged.String() != input {
+				t.Errorf("smudge(clean(x)) = %q, want %q", smudged.String(), input)
+			}
+		})
+	}
+}
+
+func TestGitFilter_SmudgePassThrough(t *testing.T) {
+	// Files committed before the filter was set up are not blocks and must check out as-is
+	for _, input := range []string{"plain file\n", "", "intro\n" + wrapper.WrapContent("x", "y")} {
+		out := &bytes.Buffer{}
+		if err := run([]string{"prompt-sanitizer", "--git-filter", "smudge"}, strings.NewReader(input), out, &bytes.Buffer{}); err != nil {
+			t.Fatalf("smudge error = %v", err)
+		}
+		if out.String() != input {
+			t.Errorf("smudge(%q) = %q, want unchanged", input, out.String())
+		}
+	}
+}
+
+func TestGitFilter_SmudgeBase64(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	wrapped := wrapper.WrapBase64([]byte(png), "logo.png") + "\n"
+	for _, args := range [][]string{{"--git-filter", "smudge"}, {"--git-filter", "smudge", "--strict-unwrap"}} {
+		out := &bytes.Buffer{}
+		if err := run(append([]string{"prompt-sanitizer"}, args...), strings.NewReader(wrapped), out, &bytes.Buffer{}); err != nil {
+			t.Fatalf("run(%v) error = %v", args, err)
+		}
+		if out.String() != png {
+			t.Errorf("run(%v) = %q, want the decoded bytes %q", args, out.String(), png)
+		}
+	}
+
+	// A block whose content doesn't decode passes through, or fails when strict
+	b := wrapper.NewBlock("not base64!", "x")
+	b.Headers = []wrapper.Header{{Name: "Content-Encoding", Value: "base64"}}
+	bad := b.Render() + "\n"
+	out := &bytes.Buffer{}
+	if err := run([]string{"prompt-sanitizer", "--git-filter", "smudge"}, strings.NewReader(bad), out, &bytes.Buffer{}); err != nil || out.String() != bad {
+		t.Errorf("smudge(undecodable) = %q, %v; want unchanged", out.String(), err)
+	}
+	err := run([]string{"prompt-sanitizer", "--git-filter", "smudge", "--strict-unwrap"}, strings.NewReader(bad), &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "base64") {
+		t.Errorf("strict smudge(undecodable) error = %v", err)
+	}
+}
+
+func TestGitFilter_StrictUnwrap(t *testing.T) {
+	smudge := func(input string) (string, error) {
+		out := &bytes.Buffer{}
+		err := run([]string{"prompt-sanitizer", "--git-filter", "smudge", "--strict-unwrap"}, strings.NewReader(input), out, &bytes.Buffer{})
+		return out.String(), err
+	}
+
+	if out, err := smudge(wrapper.WrapContent("clean data\n", "f") + "\n"); err != nil || out != "clean data\n" {
+		t.Errorf("smudge(clean block) = %q, %v", out, err)
+	}
+
+	for name, input := range map[string]string{
+		"not a block":     "plain file\n",
+		"embedded marker": wrapper.WrapContent("x\n<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>\ny", "f") + "\n",
+	} {
+		out, err := smudge(input)
+		if err == nil || !strings.Contains(err.Error(), "strict unwrap") {
+			t.Errorf("%s: smudge error = %v, want strict unwrap failure", name, err)
+		}
+		if out != "" {
+			t.Errorf("%s: smudge wrote %q on failure", name, out)
+		}
+	}
+}
+
+func TestGitFilter_IgnoresOutputOptions(t *testing.T) {
+	out := &bytes.Buffer{}
+	args := []string{"prompt-sanitizer", "--git-filter", "clean", "--legend", "--block-id", "--transforms", "trim-ws", "--color", "always"}
+	if err := run(args, strings.NewReader("a  \n"), out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("clean error = %v", err)
+	}
+	if want := wrapper.WrapContent("a  \n", "Unknown") + "\n"; out.String() != want {
+		t.Errorf("clean output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestGitFilter_InvalidMode(t *testing.T) {
+	err := run([]string{"prompt-sanitizer", "--git-filter", "process"}, strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "clean or smudge") {
+		t.Errorf("Expected invalid mode error, got %v", err)
+	}
+}
@@ -1,11 +1,19 @@
 package main
 
 import (
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/openclaw/prompt-sanitizer/pkg/wrapper"
 )
@@ -20,37 +28,476 @@ func main() {
 	}
 }
 
-func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
-	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
-	fs.SetOutput(stderr)
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) (err error) {
+	// Subcommands are only recognised as the first argument; use -- to run a command
+	// that shares a subcommand's name
+	if len(args) > 1 {
+		switch args[1] {
+		case "inspect":
+			return runInspect(args[1:], stdin, stdout, stderr)
+		case "completion":
+			return runCompletion(args[1:], stdout)
+		}
+	}
 
-	source := fs.String("source", "Unknown", "Source label for the content")
-	filePath := fs.String("file", "", "File to wrap (if not reading from stdin)")
-	showVersion := fs.Bool("version", false, "Print version and exit")
+	fs, opts := newFlagSet(args[0], stderr)
 
 	if err := fs.Parse(args[1:]); err != nil {
 		return err
 	}
 
-	if *showVersion {
+	if *opts.showVersion {
 		fmt.Fprintln(stdout, Version)
 		return nil
 	}
 
+	// An explicit --source wins over the environment, which wins over the flag default
+	sourceGiven := isFlagSet(fs, "source")
+	if env := os.Getenv(sourceEnv); !sourceGiven && env != "" {
+		*opts.source = env
+		sourceGiven = true
+	}
+
+	// Git filters must be byte-exact, so no other output options apply
+	if *opts.gitFilterMode != "" {
+		if *opts.output != "" {
+			return fmt.Errorf("--output cannot be combined with --git-filter")
+		}
+		return gitFilter(*opts.gitFilterMode, *opts.source, *opts.strictUnwrap, stdin, stdout)
+	}
+
+	var useColor bool
+	switch *opts.color {
+	case "auto":
+		useColor = isTerminal(stdout)
+	case "always":
+		useColor = true
+	case "never":
+	default:
+		return fmt.Errorf("invalid --color %q: want auto, always, or never", *opts.color)
+	}
+
+	switch *opts.compress {
+	case "":
+	case "gzip":
+		if *opts.serve || *opts.frame {
+			return fmt.Errorf("--compress cannot be combined with --serve or --frame")
+		}
+		if *opts.color == "always" {
+			return fmt.Errorf("--compress cannot be combined with --color always")
+		}
+		// Compressed bytes are never shown on a terminal, so auto never colors them
+		useColor = false
+	default:
+		return fmt.Errorf("invalid --compress %q: want gzip", *opts.compress)
+	}
+
+	// commandStderr is where command mode sends each command's stderr; nil merges it into
+	// the wrapped output
+	var commandStderr io.Writer
+	switch *opts.stderrMode {
+	case "merge":
+	case "ignore":
+		commandStderr = io.Discard
+	case "separate":
+		commandStderr = &syncWriter{w: stderr}
+	default:
+		return fmt.Errorf("invalid --stderr %q: want merge, ignore, or separate", *opts.stderrMode)
+	}
+
+	// ctx bounds command mode; it expires only when --timeout is set
+	if *opts.timeout < 0 {
+		return fmt.Errorf("invalid --timeout %s: must not be negative", *opts.timeout)
+	}
+	ctx := context.Background()
+	if *opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *opts.timeout)
+		defer cancel()
+	}
+
+	// wrapFormat renders one input for --format json or xml; nil means a marker block
+	var wrapFormat func(content, source string) (string, error)
+	switch *opts.format {
+	case "text":
+	case "json":
+		wrapFormat = wrapper.WrapJSON
+	case "xml":
+		wrapFormat = func(content, source string) (string, error) {
+			return wrapper.WrapXML(content, source), nil
+		}
+	default:
+		return fmt.Errorf("invalid --format %q: want text, json, or xml", *opts.format)
+	}
+	if wrapFormat != nil && *opts.frame {
+		return fmt.Errorf("--format %s cannot be combined with --frame", *opts.format)
+	}
+
+	var sourcePattern *regexp.Regexp
+	if *opts.sourcePattern != "" {
+		re, err := regexp.Compile(*opts.sourcePattern)
+		if err != nil {
+			return fmt.Errorf("invalid --source-pattern: %w", err)
+		}
+		sourcePattern = re
+	}
+
+	checkSource := func(source string) error {
+		if sourcePattern != nil && !sourcePattern.MatchString(source) {
+			return fmt.Errorf("source %q does not match --source-pattern %q", source, sourcePattern)
+		}
+		return nil
+	}
+
+	// render wraps content with the header options shared by every input mode
+	// asBase64 reports whether content goes into its block base64-encoded
+	asBase64 := func(content string) bool {
+		return *opts.base64 || *opts.autoBase64 && wrapper.IsBinary([]byte(content))
+	}
+
+	render := func(content, source string) (string, error) {
+		if err := checkSource(source); err != nil {
+			return "", err
+		}
+		b := wrapper.NewBlock(content, source)
+		b.NoSource = *opts.noSourceLine
+		if asBase64(content) {
+			b.EncodeBase64()
+		}
+		if *opts.blockID {
+			b.AddBlockID()
+		}
+		b.AddVia(opts.via...)
+		if *opts.canary {
+			canary, err := wrapper.NewCanary()
+			if err != nil {
+				return "", err
+			}
+			b.AddCanary(canary)
+			// The canary goes to stderr so it can be registered without parsing the output
+			fmt.Fprintf(stderr, "canary: %s\n", canary)
+		}
+		wrapped := b.Render()
+		if *opts.legend {
+			wrapped = wrapper.LegendLine(*opts.legendText) + "\n" + wrapped
+		}
+		return wrapped, nil
+	}
+
+	pipeline, transformNames, err := wrapper.ParsePipeline(*opts.transformSpec)
+	if err != nil {
+		return fmt.Errorf("invalid --transforms: %w", err)
+	}
+	// Shorthand flags append their transform after the --transforms list
+	for _, shorthand := range []struct {
+		enabled   bool
+		transform string
+	}{
+		{*opts.stripTrailingCR, "strip-trailing-cr"},
+		{*opts.neutralizeAll, "neutralize-all"},
+		{*opts.redact, "redact"},
+		{*opts.stripANSI, "strip-ansi"},
+	} {
+		if shorthand.enabled {
+			extra, names, _ := wrapper.ParsePipeline(shorthand.transform)
+			pipeline = append(pipeline, extra...)
+			transformNames = append(transformNames, names...)
+		}
+	}
+
+	var dialect wrapper.TemplateDialect
+	if *opts.escapeTemplating != "" {
+		if dialect, err = wrapper.ParseTemplateDialect(*opts.escapeTemplating); err != nil {
+			return fmt.Errorf("invalid --escape-templating: %w", err)
+		}
+	}
+
+	// prepare applies the content transforms selected by flags
+	prepare := func(content string) string {
+		content, counts := pipeline.Apply(content)
+		if *opts.stats {
+			for i, name := range transformNames {
+				fmt.Fprintf(stderr, "%s: %d changes\n", name, counts[i])
+			}
+		}
+		// Escaping runs last so the transforms see the original delimiters
+		if dialect != "" {
+			content = wrapper.EscapeTemplating(content, dialect)
+		}
+		return content
+	}
+
+	// checkInput applies the content and source checks selected by flags to prepared input
+	checkInput := func(in input) error {
+		if *opts.maxDepth >= 0 {
+			if depth := wrapper.WrapDepth(in.content); depth > *opts.maxDepth {
+				return fmt.Errorf("content is already wrapped %d layers deep (--max-depth %d)", depth, *opts.maxDepth)
+			}
+		}
+		if *opts.rejectMixedScript {
+			for _, ind := range wrapper.ScanContent(in.content) {
+				if ind.Name == wrapper.IndicatorMixedScript {
+					return fmt.Errorf("content mixes scripts within a word at offset %d: %q", ind.Offset, ind.Match)
+				}
+			}
+		}
+		if *opts.strict {
+			var found []string
+			for _, issue := range wrapper.Validate(in.content) {
+				switch issue.Kind {
+				case wrapper.ContainsStartMarker:
+					found = append(found, fmt.Sprintf("start marker at offset %d", issue.Offset))
+				case wrapper.ContainsEndMarker:
+					found = append(found, fmt.Sprintf("end marker at offset %d", issue.Offset))
+				}
+			}
+			if len(found) > 0 {
+				return fmt.Errorf("content contains wrapper markers (--strict): %s", strings.Join(found, ", "))
+			}
+		}
+		if *opts.flagBidi && wrapper.HasBidiOverride(in.content) {
+			fmt.Fprintf(stderr, "warning: content from %q contains bidi override or isolate controls that can reorder how it displays\n", in.source)
+		}
+		return checkSource(in.source)
+	}
+
+	if *opts.frame || wrapFormat != nil {
+		mode := "--frame"
+		if wrapFormat != nil {
+			mode = "--format " + *opts.format
+		}
+		for _, name := range blockOnlyFlags {
+			if isFlagSet(fs, name) {
+				return fmt.Errorf("%s carries only the source and content; it cannot be combined with --%s", mode, name)
+			}
+		}
+	}
+
+	// Files matched by a --file pattern are always labelled with their paths
+	var globbed bool
+	if opts.files, globbed, err = expandGlobs(opts.files); err != nil {
+		return err
+	}
+
+	// The --files0-from list is read up front so --output can be checked against it
+	var files0 []string
+	if *opts.files0From != "" {
+		if files0, err = readFiles0(*opts.files0From, stdin); err != nil {
+			return fmt.Errorf("reading --files0-from list: %w", err)
+		}
+	}
+
+	if *opts.output != "" {
+		if *opts.serve {
+			return fmt.Errorf("--output cannot be combined with --serve")
+		}
+		if err := checkOutputPath(*opts.output, append(files0, opts.files...), *opts.dir); err != nil {
+			return err
+		}
+		f, commit, createErr := createOutput(*opts.output)
+		if createErr != nil {
+			return fmt.Errorf("creating output file: %w", createErr)
+		}
+		defer func() {
+			if commitErr := commit(err == nil); err == nil && commitErr != nil {
+				err = fmt.Errorf("writing output file: %w", commitErr)
+			}
+		}()
+		stdout = f
+		// auto looked at the terminal, not the file
+		if *opts.color == "auto" {
+			useColor = false
+		}
+	}
+
+	if *opts.serve {
+		return serveFramed(stdin, stdout, func(content, source string) (string, error) {
+			return render(prepare(content), source)
+		})
+	}
+
+	// rawFile lets file mode wrap the file's bytes without first copying them into a string.
+	// It applies only when no option reads or rewrites the content, adds to the block, or
+	// writes anything besides the block.
+	rawFile := len(pipeline) == 0 && dialect == "" && *opts.maxDepth < 0 && !*opts.rejectMixedScript && !*opts.strict && !*opts.flagBidi &&
+		!*opts.frame && wrapFormat == nil && !useColor && *opts.compress == "" && !*opts.legend && *opts.emitSystemPrompt == "" &&
+		!*opts.blockID && !*opts.canary && len(opts.via) == 0 && !*opts.noSourceLine && !*opts.base64 && !*opts.autoBase64
+
+	// writeOutputs prepares and checks every input, then writes them in order: one frame
+	// each, JSON objects or XML elements separated by a newline, or blocks separated by a
+	// blank line. Nothing is written unless every input passes its checks.
+	writeOutputs := func(inputs []input) error {
+		for i := range inputs {
+			inputs[i].content = prepare(inputs[i].content)
+			if err := checkInput(inputs[i]); err != nil {
+				if len(inputs) > 1 {
+					return fmt.Errorf("%s: %w", inputs[i].source, err)
+				}
+				return err
+			}
+		}
+
+		if *opts.frame {
+			for _, in := range inputs {
+				if err := wrapper.WriteFrame(stdout, in.content, in.source); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		var err error
+		outputs := make([]string, len(inputs))
+		separator := "\n\n"
+		for i, in := range inputs {
+			if wrapFormat != nil {
+				if outputs[i], err = wrapFormat(in.content, in.source); err != nil {
+					return err
+				}
+				separator = "\n"
+				continue
+			}
+			if outputs[i], err = render(in.content, in.source); err != nil {
+				return err
+			}
+			if useColor {
+				content := in.content
+				if asBase64(content) {
+					content = base64.StdEncoding.EncodeToString([]byte(content))
+				}
+				outputs[i] = colorize(outputs[i], content)
+			}
+		}
+
+		if *opts.emitSystemPrompt != "" {
+			if err := writeSystemPrompt(*opts.emitSystemPrompt, *opts.promptTemplate, inputs); err != nil {
+				return err
+			}
+		}
+		out := strings.Join(outputs, separator)
+		if *opts.compress == "gzip" {
+			return writeGzip(stdout, out+"\n")
+		}
+		_, err = fmt.Fprintln(stdout, out)
+		return err
+	}
+
 	var content string
-	var err error
 
 	// Check if we have remaining args (command execution mode)
 	remainingArgs := fs.Args()
-	if len(remainingArgs) > 0 {
+	if *opts.dir != "" && (len(remainingArgs) > 0 || len(opts.alsoCmds) > 0 || len(opts.files) > 0) {
+		return fmt.Errorf("--dir cannot be combined with --file or command mode")
+	}
+	if *opts.files0From != "" && (len(remainingArgs) > 0 || len(opts.alsoCmds) > 0 || len(opts.files) > 0 || *opts.dir != "") {
+		return fmt.Errorf("--files0-from cannot be combined with --file, --dir or command mode")
+	}
+	if *opts.ext != "" && *opts.dir == "" {
+		return fmt.Errorf("--ext only applies to --dir")
+	}
+	if *opts.sourceFromCommand {
+		if len(remainingArgs) == 0 && len(opts.alsoCmds) == 0 {
+			return fmt.Errorf("--source-from-command-name only applies to command mode")
+		}
+		if isFlagSet(fs, "source") {
+			return fmt.Errorf("--source-from-command-name cannot be combined with --source")
+		}
+		commandLines := opts.alsoCmds
+		if len(remainingArgs) > 0 {
+			commandLines = append([]string{strings.Join(remainingArgs, " ")}, opts.alsoCmds...)
+		}
+		*opts.source = commandLabel(strings.Join(commandLines, "; "))
+	}
+	if isFlagSet(fs, "content") {
+		// Inline mode
+		if len(remainingArgs) > 0 || len(opts.alsoCmds) > 0 || len(opts.files) > 0 || *opts.dir != "" || *opts.files0From != "" {
+			return fmt.Errorf("--content cannot be combined with --file, --files0-from, --dir or command mode")
+		}
+		if len(*opts.content) > maxInlineContent {
+			return fmt.Errorf("--content is %d bytes; use --file or stdin for content over %d bytes", len(*opts.content), maxInlineContent)
+		}
+		content = *opts.content
+	} else if len(opts.alsoCmds) > 0 {
+		// Multi-command mode: the command after -- is [1], --also-cmd commands follow in order
+		var commands [][]string
+		if len(remainingArgs) > 0 {
+			commands = append(commands, remainingArgs)
+		}
+		for _, c := range opts.alsoCmds {
+			args, err := splitCommandLine(c)
+			if err != nil {
+				return fmt.Errorf("--also-cmd: %w", err)
+			}
+			commands = append(commands, args)
+		}
+		content, err = executeTagged(ctx, commands, commandStderr)
+		if err != nil {
+			return fmt.Errorf("executing commands: %w", timeoutError(ctx, *opts.timeout, err))
+		}
+	} else if len(remainingArgs) > 0 {
 		// Command execution mode
-		content, err = executeCommand(remainingArgs)
+		content, err = executeCommand(ctx, remainingArgs, commandStderr)
+		if err != nil {
+			return fmt.Errorf("executing command: %w", timeoutError(ctx, *opts.timeout, err))
+		}
+	} else if *opts.dir != "" {
+		// Directory mode: one block per matching file, named by its path under the directory
+		inputs, err := collectDir(*opts.dir, parseExts(*opts.ext), stderr)
+		if err != nil {
+			return fmt.Errorf("reading directory: %w", err)
+		}
+		if len(inputs) == 0 {
+			return fmt.Errorf("no files to wrap in %s", *opts.dir)
+		}
+		if sourceGiven {
+			for i := range inputs {
+				inputs[i].source = *opts.source
+			}
+		}
+		return writeOutputs(inputs)
+	} else if *opts.files0From != "" {
+		// File-list mode: one block per path in a NUL-separated list, named after its file
+		inputs := collectFiles0(files0, stderr)
+		// An empty list, as from a find that matched nothing, is not an error
+		if len(inputs) == 0 {
+			return nil
+		}
+		if sourceGiven {
+			for i := range inputs {
+				inputs[i].source = *opts.source
+			}
+		}
+		return writeOutputs(inputs)
+	} else if len(opts.files) > 1 || globbed {
+		// Multi-file mode: one block per file, each named after its file unless a source is given
+		inputs := make([]input, len(opts.files))
+		for i, path := range opts.files {
+			if inputs[i].content, err = readFile(path); err != nil {
+				return fmt.Errorf("reading file: %w", err)
+			}
+			inputs[i].source = *opts.source
+			if !sourceGiven {
+				inputs[i].source = path
+			}
+		}
+		return writeOutputs(inputs)
+	} else if len(opts.files) == 1 && rawFile {
+		// File mode, straight from bytes
+		data, err := os.ReadFile(opts.files[0])
 		if err != nil {
-			return fmt.Errorf("executing command: %w", err)
+			return fmt.Errorf("reading file: %w", err)
 		}
-	} else if *filePath != "" {
+		if err := checkSource(*opts.source); err != nil {
+			return err
+		}
+		if _, err := stdout.Write(wrapper.WrapBytes(data, *opts.source)); err != nil {
+			return err
+		}
+		_, err = io.WriteString(stdout, "\n")
+		return err
+	} else if len(opts.files) == 1 {
 		// File mode
-		content, err = readFile(*filePath)
+		content, err = readFile(opts.files[0])
 		if err != nil {
 			return fmt.Errorf("reading file: %w", err)
 		}
@@ -62,12 +509,327 @@ func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
 		}
 	}
 
-	// Wrap and output
-	wrapped := wrapper.WrapContent(content, *source)
-	fmt.Fprintln(stdout, wrapped)
+	return writeOutputs([]input{{content: content, source: *opts.source}})
+}
+
+// input is one piece of content to wrap and the source label it is wrapped under
+type input struct {
+	content string
+	source  string
+}
+
+// writeGzip writes s to w as a complete gzip stream
+func writeGzip(w io.Writer, s string) error {
+	zw := gzip.NewWriter(w)
+	if _, err := io.WriteString(zw, s); err != nil {
+		return fmt.Errorf("writing compressed output: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("writing compressed output: %w", err)
+	}
+	return nil
+}
+
+// options holds the top-level flag values
+type options struct {
+	source            *string
+	files             stringList
+	dir               *string
+	files0From        *string
+	output            *string
+	stderrMode        *string
+	timeout           *time.Duration
+	ext               *string
+	content           *string
+	showVersion       *bool
+	legend            *bool
+	legendText        *string
+	color             *string
+	blockID           *bool
+	canary            *bool
+	stripTrailingCR   *bool
+	neutralizeAll     *bool
+	redact            *bool
+	stripANSI         *bool
+	transformSpec     *string
+	stats             *bool
+	escapeTemplating  *string
+	sourcePattern     *string
+	noSourceLine      *bool
+	base64            *bool
+	autoBase64        *bool
+	sourceFromCommand *bool
+	frame             *bool
+	format            *string
+	compress          *string
+	gitFilterMode     *string
+	strictUnwrap      *bool
+	serve             *bool
+	maxDepth          *int
+	emitSystemPrompt  *string
+	promptTemplate    *string
+	rejectMixedScript *bool
+	strict            *bool
+	flagBidi          *bool
+	alsoCmds          stringList
+	via               stringList
+}
+
+// newFlagSet defines the top-level flags. Completion scripts are generated from the same
+// flag set, so a flag added here is completed without further changes.
+func newFlagSet(name string, stderr io.Writer) (*flag.FlagSet, *options) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	opts := &options{
+		source:            fs.String("source", "Unknown", "Source label for the content (falls back to $PROMPT_SANITIZER_SOURCE)"),
+		dir:               fs.String("dir", "", "Wrap every regular file under this directory, one block each, named by relative path"),
+		files0From:        fs.String("files0-from", "", "Wrap each file named in this NUL-separated list (- for stdin), as from find -print0, as its own block"),
+		stderrMode:        fs.String("stderr", "merge", "Command mode stderr: merge (into the wrapped output), ignore, or separate (forward to stderr)"),
+		sourceFromCommand: fs.Bool("source-from-command-name", false, "In command mode, use the command line as the source label (whitespace collapsed, cut at 100 characters)"),
+		timeout:           fs.Duration("timeout", 0, "Command mode: kill the command and fail if it runs longer than this, e.g. 30s (0 means no limit)"),
+		output:            fs.String("output", "", "Write the output to this file instead of stdout, creating or truncating it"),
+		ext:               fs.String("ext", "", "With --dir, only wrap files with these comma-separated extensions, e.g. .txt,.md"),
+		content:           fs.String("content", "", "Content to wrap, given inline instead of via stdin, --file or a command"),
+		showVersion:       fs.Bool("version", false, "Print version and exit"),
+		legend:            fs.Bool("legend", false, "Print a trusted legend line before the start marker"),
+		legendText:        fs.String("legend-text", wrapper.DefaultLegend, "Legend text used with --legend"),
+		color:             fs.String("color", "auto", "Highlight markers: auto (only on a terminal), always, or never"),
+		blockID:           fs.Bool("block-id", false, "Add a Block-ID header derived from the content and source"),
+		canary:            fs.Bool("canary", false, "Add a random Canary header for leak detection and print it to stderr"),
+		stripTrailingCR:   fs.Bool("strip-trailing-cr", false, "Remove carriage returns at the very end of the content"),
+		neutralizeAll:     fs.Bool("neutralize-all", false, "Rewrite every run of 3+ angle brackets in the content as entities so no marker survives"),
+		redact:            fs.Bool("redact", false, "Mask private keys, AWS access keys, bearer tokens, API keys, email addresses and password=-style secrets in the content as [REDACTED:<type>]"),
+		stripANSI:         fs.Bool("strip-ansi", false, "Remove ANSI CSI and OSC terminal escape sequences (colors, screen clears, title changes) from the content"),
+		transformSpec:     fs.String("transforms", "", "Comma-separated transforms applied in order before wrapping: "+strings.Join(wrapper.TransformNames(), ", ")),
+		stats:             fs.Bool("stats", false, "Report per-transform change counts on stderr"),
+		compress:          fs.String("compress", "", "Compress the output: gzip"),
+		format:            fs.String("format", "text", "Output format: text (a marker block), json (a {\"type\",\"source\",\"content\"} object), or xml (an <untrusted source=...> element)"),
+		frame:             fs.Bool("frame", false, "Write a length-prefixed binary frame instead of a text block, for IPC"),
+		noSourceLine:      fs.Bool("no-source-line", false, "Omit the Source: line when provenance is carried outside the block"),
+		base64:            fs.Bool("base64", false, "Base64-encode the content and add a Content-Encoding: base64 header, for binary input"),
+		autoBase64:        fs.Bool("auto-base64", false, "Like --base64, but only for content that looks binary (a NUL byte, or over 30% control or invalid bytes)"),
+		sourcePattern:     fs.String("source-pattern", "", "Reject sources not matching this regular expression (unanchored; use ^...$ for a full match)"),
+		escapeTemplating:  fs.String("escape-templating", "", "Escape template delimiters in the content for a downstream templater: go, jinja, or shell"),
+		gitFilterMode:     fs.String("git-filter", "", "Act as a git filter: clean wraps stdin, smudge unwraps it"),
+		strictUnwrap:      fs.Bool("strict-unwrap", false, "With --git-filter smudge, refuse anything but an unambiguous block (no marker-like text inside)"),
+		serve:             fs.Bool("serve", false, "Wrap length-prefixed requests from stdin until it closes (see README for framing)"),
+		emitSystemPrompt:  fs.String("emit-system-prompt", "", "Also write a system prompt naming the markers as untrusted boundaries to this file"),
+		promptTemplate:    fs.String("system-prompt-template", "", "File with a text/template for --emit-system-prompt ({{.StartMarker}}, {{.EndMarker}}, {{.Source}})"),
+		maxDepth:          fs.Int("max-depth", -1, "Refuse content already wrapped in more than N layers (-1 for no limit)"),
+		rejectMixedScript: fs.Bool("reject-mixed-script", false, "Refuse content with words mixing lookalike scripts (homoglyphs)"),
+		strict:            fs.Bool("strict", false, "Fail instead of wrapping content that contains the start or end marker, listing their offsets"),
+		flagBidi:          fs.Bool("flag-bidi", false, "Warn on stderr when content holds bidi override, embedding or isolate controls (U+202A-U+202E, U+2066-U+2069)"),
+	}
+	fs.Var(&opts.files, "file", "File to wrap (if not reading from stdin); repeat, or give a glob such as 'logs/*.txt', to wrap several files as separate blocks")
+	fs.Var(&opts.alsoCmds, "also-cmd", "Run another command alongside command mode and tag each output line with its command number (repeatable); split on whitespace, with single or double quotes grouping words, and not run through a shell")
+	fs.Var(&opts.via, "via", "Add a provenance hop as a Via header (repeatable, in order from origin)")
+	return fs, opts
+}
+
+// mixedSourcesLabel stands in for the source in the system prompt when the blocks come from
+// several sources, such as the files of --dir
+const mixedSourcesLabel = "the source named on each block's Source line"
+
+// writeSystemPrompt writes the companion system prompt for the blocks wrapping inputs to
+// path, naming their source when they all share one
+func writeSystemPrompt(path, templatePath string, inputs []input) error {
+	source := mixedSourcesLabel
+	if len(inputs) > 0 {
+		source = inputs[0].source
+		for _, in := range inputs[1:] {
+			if in.source != source {
+				source = mixedSourcesLabel
+				break
+			}
+		}
+	}
+
+	var tmpl string
+	if templatePath != "" {
+		var err error
+		if tmpl, err = readFile(templatePath); err != nil {
+			return fmt.Errorf("reading system prompt template: %w", err)
+		}
+	}
+	prompt, err := wrapper.SystemPrompt(tmpl, wrapper.NewBlock("", source))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(prompt+"\n"), 0o644); err != nil {
+		return fmt.Errorf("writing system prompt: %w", err)
+	}
+	return nil
+}
+
+// blockOnlyFlags shape the text block, which binary frames and JSON output do not have
+var blockOnlyFlags = []string{"serve", "legend", "legend-text", "block-id", "canary", "via", "no-source-line", "color", "emit-system-prompt", "base64", "auto-base64"}
+
+// sourceEnv names the environment variable used as the source label when --source is not given
+const sourceEnv = "PROMPT_SANITIZER_SOURCE"
+
+// maxInlineContent caps --content; longer content belongs in a file, not the argument list
+const maxInlineContent = 16 * 1024
+
+// checkOutputPath refuses an --output path that would truncate an input before it is read:
+// one of the --file or --files0-from paths, or a file inside the --dir tree
+func checkOutputPath(output string, files []string, dir string) error {
+	for _, file := range files {
+		if samePath(output, file) {
+			return fmt.Errorf("--output %s is also an input file; refusing to truncate it", output)
+		}
+	}
+	if dir != "" {
+		absDir, err1 := filepath.Abs(dir)
+		absOut, err2 := filepath.Abs(output)
+		if err1 == nil && err2 == nil {
+			rel, err := filepath.Rel(absDir, absOut)
+			if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return fmt.Errorf("--output %s is inside --dir %s; it would be wrapped as an input", output, dir)
+			}
+		}
+	}
+	return nil
+}
+
+// createOutput opens a temporary file beside path for --output. commit closes it and, when
+// ok, renames it over path; otherwise it removes it, so a failed run leaves an earlier
+// result at path untouched. The file takes the permissions of the file it replaces, or 0644.
+func createOutput(path string) (f *os.File, commit func(ok bool) error, err error) {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	f, err = os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, nil, err
+	}
+	commit = func(ok bool) error {
+		err := f.Close()
+		if ok && err == nil {
+			err = os.Rename(f.Name(), path)
+		}
+		if !ok || err != nil {
+			os.Remove(f.Name())
+		}
+		return err
+	}
+	return f, commit, nil
+}
+
+// samePath reports whether a and b name the same file. Existing files are compared by
+// identity, so links and differently spelled paths match; otherwise the absolute paths are.
+func samePath(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA == nil && errB == nil {
+		return os.SameFile(infoA, infoB)
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// isFlagSet reports whether the named flag was given on the command line, even if empty
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// stringList collects the values of a repeatable flag in order
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ", ") }
+
+func (l *stringList) Set(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("empty value")
+	}
+	*l = append(*l, value)
 	return nil
 }
 
+// runInspect prints the parsed structure of a wrapped block as JSON. The content is
+// never acted on, only measured.
+func runInspect(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs, filePath := newInspectFlagSet(args[0], stderr)
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	var wrapped string
+	var err error
+	if *filePath != "" {
+		wrapped, err = readFile(*filePath)
+		if err != nil {
+			return fmt.Errorf("reading file: %w", err)
+		}
+	} else {
+		wrapped, err = readFromReader(stdin)
+		if err != nil {
+			return fmt.Errorf("reading stdin: %w", err)
+		}
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(wrapper.Inspect(wrapped))
+}
+
+// newInspectFlagSet defines the inspect subcommand's flags
+func newInspectFlagSet(name string, stderr io.Writer) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	filePath := fs.String("file", "", "File containing the wrapped block (if not reading from stdin)")
+	return fs, filePath
+}
+
+const (
+	ansiMarker = "\x1b[1;36m"
+	ansiReset  = "\x1b[0m"
+)
+
+// colorize highlights the lines the tool added around content, leaving content untouched.
+// The wrapped form is always header + content + "\n" + end marker, so the header ends
+// len(content) bytes before the last newline.
+func colorize(wrapped, content string) string {
+	footerStart := strings.LastIndex(wrapped, "\n")
+	headerEnd := footerStart - len(content)
+	return colorLines(wrapped[:headerEnd]) + content + "\n" + colorLines(wrapped[footerStart+1:])
+}
+
+// colorLines colors each line of s separately so no escape code spans a newline
+func colorLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = ansiMarker + line + ansiReset
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isTerminal reports whether w is a character device such as an interactive terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
 func readFromReader(r io.Reader) (string, error) {
 	bytes, err := io.ReadAll(r)
 	if err != nil {
@@ -84,11 +846,53 @@ func readFile(path string) (string, error) {
 	return string(bytes), nil
 }
 
-func executeCommand(args []string) (string, error) {
-	cmd := exec.Command(args[0], args[1:]...)
-	output, err := cmd.CombinedOutput()
+// executeCommand runs args and returns its output. A nil errOut merges the command's
+// stderr into the output; otherwise stderr goes to errOut and only stdout is returned.
+func executeCommand(ctx context.Context, args []string, errOut io.Writer) (string, error) {
+	cmd := newCommand(ctx, args)
+	var output []byte
+	var err error
+	if errOut == nil {
+		output, err = cmd.CombinedOutput()
+	} else {
+		cmd.Stderr = errOut
+		output, err = cmd.Output()
+	}
 	if err != nil {
 		return "", fmt.Errorf("command failed: %w", err)
 	}
 	return string(output), nil
 }
+
+// maxCommandLabel is the longest command line, in runes, that commandLabel keeps
+const maxCommandLabel = 100
+
+// commandLabel turns a command line into a source label: runs of whitespace, newlines
+// included, become one space so the label stays on the Source line, and anything past
+// maxCommandLabel runes is cut and marked with "..."
+func commandLabel(line string) string {
+	label := strings.Join(strings.Fields(line), " ")
+	if runes := []rune(label); len(runes) > maxCommandLabel {
+		label = string(runes[:maxCommandLabel]) + "..."
+	}
+	return label
+}
+
+// newCommand prepares args to run under ctx. When ctx ends the whole process group is
+// killed, so a shell's children die with it, and Wait stops waiting on pipes that an
+// escaped grandchild still holds open.
+func newCommand(ctx context.Context, args []string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	killProcessGroup(cmd)
+	cmd.WaitDelay = time.Second
+	return cmd
+}
+
+// timeoutError replaces err with a timeout error when ctx expired, since the command's own
+// error is then just the kill signal
+func timeoutError(ctx context.Context, timeout time.Duration, err error) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("command timed out after %s (--timeout) and was killed", timeout)
+	}
+	return err
+}
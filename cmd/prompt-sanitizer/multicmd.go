@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// executeTagged runs the commands concurrently and merges their combined output line by line
+// as it arrives, prefixing each line with "[N] " where N is the command's 1-based position.
+//
+// Every line gets exactly one tag at its start, so a command printing "[2] ..." shows up as
+// "[1] [2] ...": it can't start a line of its own. CRLF ends a line, and so does a bare CR,
+// which would otherwise let a command move the cursor back over its own tag on a terminal.
+//
+// A nil errOut merges each command's stderr into its tagged lines; otherwise stderr goes to
+// errOut untagged, and errOut must be safe for concurrent writes.
+func executeTagged(ctx context.Context, commands [][]string, errOut io.Writer) (string, error) {
+	var (
+		mu  sync.Mutex
+		out strings.Builder
+		wg  sync.WaitGroup
+	)
+	errs := make([]error, len(commands))
+
+	for i, args := range commands {
+		pr, pw := io.Pipe()
+		cmd := newCommand(ctx, args)
+		cmd.Stdout = pw
+		cmd.Stderr = pw
+		if errOut != nil {
+			cmd.Stderr = errOut
+		}
+		if err := cmd.Start(); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			errs[i] = cmd.Wait()
+			pw.Close()
+		}()
+		go func() {
+			defer wg.Done()
+			tag := fmt.Sprintf("[%d] ", i+1)
+			r := bufio.NewReader(pr)
+			for {
+				text, err := r.ReadString('\n')
+				if text != "" {
+					text = strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r")
+					mu.Lock()
+					for _, line := range strings.Split(text, "\r") {
+						out.WriteString(tag + line + "\n")
+					}
+					mu.Unlock()
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return "", fmt.Errorf("command %d failed: %w", i+1, err)
+		}
+	}
+	return out.String(), nil
+}
+
+// syncWriter serializes writes to w, for sharing one stderr between concurrent commands
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// splitCommandLine splits an --also-cmd value into arguments. Whitespace separates them, and
+// single or double quotes group text, spaces included, into one argument, so 'a b' and "a b"
+// both give the argument a b. Inside double quotes a backslash escapes a double quote or a
+// backslash; anywhere else backslashes are kept as written. There is no other shell syntax,
+// no variables, globs or pipes, and an unclosed quote is an error.
+func splitCommandLine(line string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote byte // the open quote character, or 0
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				arg.WriteByte(c)
+			}
+		case quote == '"':
+			switch {
+			case c == '"':
+				quote = 0
+			case c == '\\' && i+1 < len(line) && (line[i+1] == '"' || line[i+1] == '\\'):
+				i++
+				arg.WriteByte(line[i])
+			default:
+				arg.WriteByte(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteByte(c)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unclosed %c quote in %q", quote, line)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return args, nil
+}
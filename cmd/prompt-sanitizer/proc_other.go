@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+import "os/exec"
+
+// killProcessGroup leaves cmd as is: without process groups, cancellation kills only the
+// direct child
+func killProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxFrameField caps a single framed field so a bad length can't force a huge allocation
+const maxFrameField = 1 << 30
+
+// errProtocol is wrapped by every framing error in --serve mode
+var errProtocol = errors.New("protocol error")
+
+// serveFramed answers framed wrap requests until stdin closes. Each request is
+//
+//	<source-len>\n<source>\n<content-len>\n<content>
+//
+// with lengths as decimal byte counts and no terminator after the content. Each response is
+//
+//	<wrapped-len>\n<wrapped>\n
+//
+// flushed immediately. EOF between requests ends the loop cleanly; any malformed frame
+// is a protocol error that stops serving.
+func serveFramed(stdin io.Reader, stdout io.Writer, render func(content, source string) (string, error)) error {
+	r := bufio.NewReader(stdin)
+	w := bufio.NewWriter(stdout)
+
+	for n := 1; ; n++ {
+		sourceLen, err := readFrameLength(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("request %d: source length: %w", n, err)
+		}
+		source, err := readFrameField(r, sourceLen)
+		if err != nil {
+			return fmt.Errorf("request %d: source: %w", n, err)
+		}
+		if b, err := r.ReadByte(); err != nil || b != '\n' {
+			return fmt.Errorf("request %d: %w: source not followed by newline", n, errProtocol)
+		}
+
+		contentLen, err := readFrameLength(r)
+		if err != nil {
+			return fmt.Errorf("request %d: content length: %w", n, unexpectedEOF(err))
+		}
+		content, err := readFrameField(r, contentLen)
+		if err != nil {
+			return fmt.Errorf("request %d: content: %w", n, err)
+		}
+
+		wrapped, err := render(content, source)
+		if err != nil {
+			return fmt.Errorf("request %d: %w", n, err)
+		}
+		fmt.Fprintf(w, "%d\n%s\n", len(wrapped), wrapped)
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("writing response %d: %w", n, err)
+		}
+	}
+}
+
+// readFrameLength reads a decimal length line. A bare io.EOF means no more requests.
+func readFrameLength(r *bufio.Reader) (int, error) {
+	line, err := r.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return 0, io.EOF
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%w: truncated length line", errProtocol)
+	}
+	digits := strings.TrimSuffix(line, "\n")
+	n, err := strconv.Atoi(digits)
+	if err != nil || n < 0 || digits != strconv.Itoa(n) {
+		return 0, fmt.Errorf("%w: invalid length %q", errProtocol, digits)
+	}
+	if n > maxFrameField {
+		return 0, fmt.Errorf("%w: length %d exceeds limit %d", errProtocol, n, maxFrameField)
+	}
+	return n, nil
+}
+
+// readFrameField reads exactly n bytes
+func readFrameField(r *bufio.Reader, n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", fmt.Errorf("%w: expected %d bytes: %v", errProtocol, n, err)
+	}
+	return string(buf), nil
+}
+
+// unexpectedEOF turns a clean EOF inside a request into a protocol error
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return fmt.Errorf("%w: request ended early", errProtocol)
+	}
+	return err
+}
@@ -2,4 +2,7 @@ module github.com/openclaw/prompt-sanitizer
 
 go 1.22.2
 
-require gopkg.in/yaml.v3 v3.0.1 // indirect
+require (
+	golang.org/x/text v0.22.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,90 @@
+package wrapper
+
+import "strings"
+
+// terminalEscape returns the length of the CSI or OSC sequence at the start of s, or 0 if
+// s doesn't start with one. A CSI sequence (ESC [) runs through its parameter and
+// intermediate bytes to a final byte in 0x40-0x7E; an OSC sequence (ESC ]) runs to a BEL or
+// an ST (ESC \). An unterminated sequence covers only its introducer and, for CSI, the
+// parameters read so far, so stripping it leaves the text that follows.
+func terminalEscape(s string) int {
+	if len(s) < 2 || s[0] != '\x1b' {
+		return 0
+	}
+	switch s[1] {
+	case '[':
+		i := 2
+		for i < len(s) && s[i] >= 0x30 && s[i] <= 0x3f {
+			i++
+		}
+		for i < len(s) && s[i] >= 0x20 && s[i] <= 0x2f {
+			i++
+		}
+		if i < len(s) && s[i] >= 0x40 && s[i] <= 0x7e {
+			i++
+		}
+		return i
+	case ']':
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\x07' {
+				return i + 1
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return 2
+	}
+	return 0
+}
+
+// ContainsTerminalEscapes reports whether content holds an ANSI CSI sequence (ESC [, as in
+// colors and screen clears) or an OSC sequence (ESC ], as in terminal title changes).
+// Other lone ESC bytes don't count.
+func ContainsTerminalEscapes(content string) bool {
+	for i := strings.IndexByte(content, '\x1b'); i >= 0; {
+		if terminalEscape(content[i:]) > 0 {
+			return true
+		}
+		next := strings.IndexByte(content[i+1:], '\x1b')
+		if next < 0 {
+			break
+		}
+		i += 1 + next
+	}
+	return false
+}
+
+// StripTerminalEscapes removes the CSI and OSC sequences ContainsTerminalEscapes detects,
+// so content can't recolor, clear or retitle a terminal it is printed to. Other control
+// characters are left alone, except ESC bytes directly before a removed sequence. This is
+// also available as the strip-ansi transform.
+func StripTerminalEscapes(content string) string {
+	stripped, _ := stripTerminalEscapes(content)
+	return stripped
+}
+
+// stripTerminalEscapes is StripTerminalEscapes counting the sequences removed
+func stripTerminalEscapes(content string) (string, int) {
+	if strings.IndexByte(content, '\x1b') < 0 {
+		return content, 0
+	}
+	out := make([]byte, 0, len(content))
+	removed := 0
+	for i := 0; i < len(content); {
+		n := terminalEscape(content[i:])
+		if n == 0 {
+			out = append(out, content[i])
+			i++
+			continue
+		}
+		removed++
+		i += n
+		// Lone ESCs just before a sequence would join whatever follows it, so
+		// "\x1b\x1b[0m[31m" can't turn into "\x1b[31m"
+		for len(out) > 0 && out[len(out)-1] == '\x1b' {
+			out = out[:len(out)-1]
+		}
+	}
+	return string(out), removed
+}
@@ -0,0 +1,40 @@
+package wrapper
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// redactedText replaces each span matched by an audit pattern
+const redactedText = "[REDACTED]"
+
+// DefaultAuditPatterns match common personal data and credentials for WrapWithRedactedAudit.
+// They are the patterns Redact uses, in the same order.
+var DefaultAuditPatterns = func() []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, len(secretPatterns))
+	for i, s := range secretPatterns {
+		patterns[i] = s.pattern
+	}
+	return patterns
+}()
+
+// WrapWithRedactedAudit wraps content for the model and writes a second, redacted block to
+// auditW for logging. Every span matching one of patterns is replaced with [REDACTED] in the
+// audit copy only; source and structure are the same in both. A nil patterns slice uses
+// DefaultAuditPatterns. The real block is returned even when writing the audit copy fails.
+func WrapWithRedactedAudit(content, source string, auditW io.Writer, patterns []*regexp.Regexp) (string, error) {
+	if patterns == nil {
+		patterns = DefaultAuditPatterns
+	}
+	redacted := content
+	for _, p := range patterns {
+		redacted = p.ReplaceAllLiteralString(redacted, redactedText)
+	}
+
+	wrapped := WrapContent(content, source)
+	if _, err := io.WriteString(auditW, WrapContent(redacted, source)+"\n"); err != nil {
+		return wrapped, fmt.Errorf("writing audit copy: %w", err)
+	}
+	return wrapped, nil
+}
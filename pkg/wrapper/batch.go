@@ -0,0 +1,47 @@
+package wrapper
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBatchBuffer caps the buffers WrapBatch returns to its pool, so one huge batch
+// doesn't pin its buffer for the life of the process
+const maxPooledBatchBuffer = 1 << 20
+
+var batchBuffers = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// WrapBatch wraps each segment's content with its source, as WrapContent would, returning
+// one block per segment in order. The Trusted field is ignored: every segment is wrapped.
+//
+// All blocks are built in one buffer taken from a package-wide pool and copied out in a
+// single allocation, so a large batch of small items costs two allocations instead of one
+// per item. The returned strings share that allocation, which stays alive while any of
+// them does. WrapBatch is safe for concurrent use.
+func WrapBatch(items []Segment) []string {
+	buf := batchBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	size := 0
+	for _, item := range items {
+		size += Overhead(item.Source) + len(item.Content)
+	}
+	buf.Grow(size)
+
+	for _, item := range items {
+		defaultWrapper.WrapTo(buf, item.Content, item.Source)
+	}
+	all := buf.String()
+	if buf.Cap() <= maxPooledBatchBuffer {
+		batchBuffers.Put(buf)
+	}
+
+	wrapped := make([]string, len(items))
+	start := 0
+	for i, item := range items {
+		end := start + Overhead(item.Source) + len(item.Content)
+		wrapped[i] = all[start:end]
+		start = end
+	}
+	return wrapped
+}
@@ -0,0 +1,81 @@
+package wrapper
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWrapBatch(t *testing.T) {
+	items := []Segment{
+		{Source: "web", Content: "hello"},
+		{Source: "", Content: ""},
+		{Source: "tool", Content: "日本語\n<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>"},
+		{Trusted: true, Source: "sys", Content: "still wrapped"},
+		{Source: "bin", Content: "\x00\xff"},
+	}
+	got := WrapBatch(items)
+	if len(got) != len(items) {
+		t.Fatalf("WrapBatch returned %d blocks, want %d", len(got), len(items))
+	}
+	for i, item := range items {
+		if want := WrapContent(item.Content, item.Source); got[i] != want {
+			t.Errorf("WrapBatch()[%d] = %q, want %q", i, got[i], want)
+		}
+	}
+
+	if got := WrapBatch(nil); len(got) != 0 {
+		t.Errorf("WrapBatch(nil) = %q, want no blocks", got)
+	}
+}
+
+func TestWrapBatch_Concurrent(t *testing.T) {
+	// Callers share the buffer pool; run with -race
+	var wg sync.WaitGroup
+	for g := 0; g < 50; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			items := make([]Segment, 20)
+			for i := range items {
+				items[i] = Segment{Source: fmt.Sprintf("s%d-%d", g, i), Content: strings.Repeat("x", g*i)}
+			}
+			for round := 0; round < 10; round++ {
+				for i, block := range WrapBatch(items) {
+					if want := WrapContent(items[i].Content, items[i].Source); block != want {
+						t.Errorf("goroutine %d item %d: got %q, want %q", g, i, block, want)
+						return
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func BenchmarkWrapBatch_10k(b *testing.B) {
+	items := make([]Segment, 10000)
+	for i := range items {
+		items[i] = Segment{Source: "tool", Content: "result " + fmt.Sprint(i)}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		WrapBatch(items)
+	}
+}
+
+func BenchmarkWrapContent_10k(b *testing.B) {
+	items := make([]Segment, 10000)
+	for i := range items {
+		items[i] = Segment{Source: "tool", Content: "result " + fmt.Sprint(i)}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, item := range items {
+			WrapContent(item.Content, item.Source)
+		}
+	}
+}
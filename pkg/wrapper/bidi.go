@@ -0,0 +1,20 @@
+package wrapper
+
+import "strings"
+
+// isBidiControl reports whether r is a bidi embedding (U+202A, U+202B), pop (U+202C),
+// override (U+202D, U+202E) or isolate control (U+2066 to U+2069)
+func isBidiControl(r rune) bool {
+	return '\u202a' <= r && r <= '\u202e' || '\u2066' <= r && r <= '\u2069'
+}
+
+// HasBidiOverride reports whether content holds a bidi embedding, override or isolate
+// control, the characters Trojan Source attacks use to make text display in a different
+// order from the one a model or compiler reads it in.
+//
+// Right-to-left text doesn't need them: Arabic and Hebrew letters carry their own direction,
+// and the directional marks U+200E, U+200F and U+061C only nudge neutral characters such as
+// punctuation without reordering anything, so none of those count.
+func HasBidiOverride(content string) bool {
+	return strings.IndexFunc(content, isBidiControl) >= 0
+}
@@ -0,0 +1,44 @@
+package wrapper
+
+import "unicode/utf8"
+
+// binarySniffLen is how much of the content IsBinary looks at
+const binarySniffLen = 8 << 10
+
+// binaryThreshold is the share of non-printable bytes above which content counts as binary
+const binaryThreshold = 0.30
+
+// IsBinary guesses whether content is binary data rather than text, looking at the first
+// 8 KiB: any NUL byte makes it binary, as does more than 30% of the bytes being control
+// characters other than common whitespace or part of invalid UTF-8. Valid UTF-8 text in
+// any script, emoji included, counts as text. A rune cut off at the 8 KiB mark is not
+// held against the content.
+func IsBinary(content []byte) bool {
+	sample := content
+	if len(sample) > binarySniffLen {
+		sample = sample[:binarySniffLen]
+	}
+	if len(sample) == 0 {
+		return false
+	}
+
+	nonPrintable := 0
+	for i := 0; i < len(sample); {
+		r, size := utf8.DecodeRune(sample[i:])
+		switch {
+		case r == 0:
+			return true
+		case r == utf8.RuneError && size == 1:
+			if len(content) > len(sample) && !utf8.FullRune(sample[i:]) {
+				// The sample ends partway through a rune
+				i = len(sample)
+				continue
+			}
+			nonPrintable++
+		case r < 0x20 && r != '\t' && r != '\n' && r != '\r' && r != '\f' && r != '\v', r == 0x7f:
+			nonPrintable++
+		}
+		i += size
+	}
+	return float64(nonPrintable) > binaryThreshold*float64(len(sample))
+}
@@ -0,0 +1,51 @@
+package wrapper
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"empty", nil, false},
+		{"ascii", []byte("hello world\n"), false},
+		{"large ascii", bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog.\n"), 1000), false},
+		{"unicode", []byte("日本語 \U0001F980 مرحبا"), false},
+		{"emoji", []byte(strings.Repeat("\U0001F600\U0001F44B\U0001F3FD", 50)), false},
+		{"whitespace controls", []byte("a\tb\r\nc\fd\ve"), false},
+		{"ansi colors", []byte("\x1b[31mred\x1b[0m and plain text"), false},
+		{"null-heavy", []byte{0x00, 0x00, 0x00, 0x00, 'H', 'I', 0x00, 0x00}, true},
+		{"single nul", []byte("text with one \x00 byte"), true},
+		{"PNG header", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), true},
+		{"high bytes", []byte{0xFF, 0xFE, 0xFD, 0xFC, 0xFB, 0xFA}, true},
+		{"random-looking bytes", []byte{0xde, 0xad, 0xbe, 0xef, 0xca, 0xfe, 0xba, 0xbe}, true},
+		{"mostly text, some invalid", []byte("plain text with a stray \xff byte"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBinary(tt.data); got != tt.want {
+				t.Errorf("IsBinary(%q) = %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBinary_SniffWindow(t *testing.T) {
+	// Only the first 8 KiB count
+	text := bytes.Repeat([]byte("a"), 8<<10)
+	if IsBinary(append(text, 0)) {
+		t.Error("a NUL after the first 8 KiB should not count")
+	}
+
+	// A CJK rune straddling the 8 KiB mark is still text
+	cjk := bytes.Repeat([]byte("日"), 3000)
+	if IsBinary(cjk) {
+		t.Error("CJK text split at the sniff window should be text")
+	}
+}
@@ -0,0 +1,161 @@
+package wrapper
+
+import "strings"
+
+// Header is a "Name: value" line between the source line and the separator
+type Header struct {
+	Name  string
+	Value string
+}
+
+// Block is the parsed form of a single wrapped block. Rendering an unmodified Block
+// reproduces the text it was parsed from byte for byte.
+type Block struct {
+	StartMarker string
+	Source      string
+	NoSource    bool     // the block has no Source line, as with --no-source-line; Source is empty
+	Headers     []Header // extra header lines in order; order is kept so Render round-trips
+	Separator   string
+	Content     string
+	EndMarker   string
+
+	// Start and End are the byte offsets of the block in the document FindBlocks found it
+	// in, end exclusive; they are zero for blocks from anywhere else and Render ignores them
+	Start, End int
+}
+
+// NewBlock returns the block WrapContent would produce for content and source
+func NewBlock(content, source string) *Block {
+	return &Block{
+		StartMarker: StartMarker,
+		Source:      source,
+		Separator:   Separator,
+		Content:     content,
+		EndMarker:   EndMarker,
+	}
+}
+
+// Header returns the value of the first header called name and whether it was present
+func (b *Block) Header(name string) (string, bool) {
+	for _, h := range b.Headers {
+		if h.Name == name {
+			return h.Value, true
+		}
+	}
+	return "", false
+}
+
+// Render serializes the block back to wrapped text
+func (b *Block) Render() string {
+	var sb strings.Builder
+	sb.WriteString(b.StartMarker + "\n")
+	if !b.NoSource {
+		sb.WriteString(SourcePrefix + b.Source + "\n")
+	}
+	for _, h := range b.Headers {
+		sb.WriteString(h.Name + ": " + h.Value + "\n")
+	}
+	sb.WriteString(b.Separator + "\n" + b.Content + "\n" + b.EndMarker)
+	return sb.String()
+}
+
+// ParseBlock parses wrapped text that is exactly one block: the start marker on the first
+// line, the matching end marker on the last, and nothing before or after, not even the
+// newline the CLI prints. A block without a Source line parses with NoSource set.
+// Nonce-suffixed markers must carry the same nonce. Failures are *MalformedError values
+// matching ErrUnwrapMalformed.
+func ParseBlock(wrapped string) (*Block, error) {
+	first, rest, found := strings.Cut(wrapped, "\n")
+	if !isStartMarker(first) {
+		return nil, &MalformedError{Offset: 0, Reason: "first line is not a start marker"}
+	}
+	if !found {
+		return nil, &MalformedError{Offset: len(wrapped), Reason: "missing source line"}
+	}
+	b := &Block{StartMarker: first, Separator: Separator, EndMarker: EndMarker}
+	if first != StartMarker {
+		nonce := strings.TrimSuffix(strings.TrimPrefix(first, strings.TrimSuffix(StartMarker, ">>>")+":"), ">>>")
+		b.EndMarker = nonceMarker(EndMarker, nonce)
+	}
+
+	interior, found := strings.CutSuffix(rest, "\n"+b.EndMarker)
+	if !found {
+		return nil, &MalformedError{Offset: len(wrapped), Reason: "last line is not the matching end marker " + b.EndMarker}
+	}
+
+	if err := parseInterior(b, interior, len(first)+1); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// parseInterior parses the text between a block's marker lines into b: the Source line if
+// present, any header lines and the separator, leaving the rest as b.Content. offset is
+// where interior starts in the wrapped text, for error offsets.
+func parseInterior(b *Block, interior string, offset int) *MalformedError {
+	line, rest, found := strings.Cut(interior, "\n")
+	if source, ok := strings.CutPrefix(line, SourcePrefix); ok {
+		b.Source = source
+		offset += len(line) + 1
+		if !found {
+			return &MalformedError{Offset: offset, Reason: "missing separator line"}
+		}
+		line, rest, found = strings.Cut(rest, "\n")
+	} else if line != Separator && !isHeaderLine(line) {
+		return &MalformedError{Offset: offset, Reason: "missing source line"}
+	} else {
+		b.NoSource = true
+	}
+
+	for line != Separator {
+		if !isHeaderLine(line) {
+			return &MalformedError{Offset: offset, Reason: "expected header or separator line"}
+		}
+		name, value, _ := strings.Cut(line, ": ")
+		b.Headers = append(b.Headers, Header{Name: name, Value: value})
+		offset += len(line) + 1
+		if !found {
+			return &MalformedError{Offset: offset, Reason: "missing separator line"}
+		}
+		line, rest, found = strings.Cut(rest, "\n")
+	}
+	if !found {
+		// WrapContent always puts a content line, possibly empty, after the separator
+		return &MalformedError{Offset: offset + len(line), Reason: "missing content line after separator"}
+	}
+	b.Content = rest
+	return nil
+}
+
+// Unwrap returns the content and source label of a single wrapped block, the inverse of
+// WrapContent for single-line sources: Unwrap(WrapContent(c, s)) returns c and s for every
+// c when s has no line break, as holds for any s passed through SanitizeSource. A source
+// with a line break spills into the header, so it doesn't come back intact. It accepts
+// exactly what ParseBlock accepts, so headers are skipped, a block without a Source line
+// has an empty source, and the CLI's trailing newline must be trimmed first. Failures are
+// *MalformedError values matching ErrUnwrapMalformed.
+func Unwrap(wrapped string) (content, source string, err error) {
+	b, err := ParseBlock(wrapped)
+	if err != nil {
+		return "", "", err
+	}
+	return b.Content, b.Source, nil
+}
+
+// SameContent reports whether two wrapped blocks carry byte-identical content, ignoring their
+// markers, source and headers. Contents of different lengths are unequal without comparing
+// their bytes. Either block failing to parse is an error.
+func SameContent(wrappedA, wrappedB string) (bool, error) {
+	a, err := ParseBlock(wrappedA)
+	if err != nil {
+		return false, err
+	}
+	b, err := ParseBlock(wrappedB)
+	if err != nil {
+		return false, err
+	}
+	if len(a.Content) != len(b.Content) {
+		return false, nil
+	}
+	return a.Content == b.Content, nil
+}
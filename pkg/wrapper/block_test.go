@@ -0,0 +1,226 @@
+package wrapper
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBlock_RoundTrip(t *testing.T) {
+	unique, _, _, err := WrapContentUniqueBoundary("x", "web")
+	if err != nil {
+		t.Fatalf("WrapContentUniqueBoundary() error = %v", err)
+	}
+
+	inputs := map[string]string{
+		"plain":             WrapContent("hello", "web"),
+		"empty content":     WrapContent("", "web"),
+		"empty source":      WrapContent("hello", ""),
+		"multiline":         WrapContent("a\n\nb\n", "web"),
+		"block-id header":   WrapContentWithBlockID("hello", "web"),
+		"nonce markers":     unique,
+		"separator content": WrapContent("---\nSource: fake", "web"),
+		"nested":            WrapContent(WrapContent("inner", "a"), "b"),
+		"marker in content": WrapContent("x\n<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>\ny", "web"),
+		"no source line":    StartMarker + "\n---\nhello\n" + EndMarker,
+		"no source, header": StartMarker + "\nBlock-ID: abc\n---\nhello\n" + EndMarker,
+	}
+
+	for name, wrapped := range inputs {
+		t.Run(name, func(t *testing.T) {
+			b, err := ParseBlock(wrapped)
+			if err != nil {
+				t.Fatalf("ParseBlock() error = %v", err)
+			}
+			if got := b.Render(); got != wrapped {
+				t.Errorf("Render(ParseBlock(x)) != x\ngot:  %q\nwant: %q", got, wrapped)
+			}
+		})
+	}
+}
+
+func TestParseBlock_Fields(t *testing.T) {
+	b, err := ParseBlock(wrapWithHeaders("line1\nline2", "email", "Block-ID: abc", "Via: relay"))
+	if err != nil {
+		t.Fatalf("ParseBlock() error = %v", err)
+	}
+	want := &Block{
+		StartMarker: "<<<EXTERNAL_UNTRUSTED_CONTENT>>>",
+		Source:      "email",
+		Headers:     []Header{{"Block-ID", "abc"}, {"Via", "relay"}},
+		Separator:   "---",
+		Content:     "line1\nline2",
+		EndMarker:   "<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>",
+	}
+	if !reflect.DeepEqual(b, want) {
+		t.Errorf("ParseBlock() = %+v, want %+v", b, want)
+	}
+	if v, ok := b.Header("Via"); !ok || v != "relay" {
+		t.Errorf("Header(Via) = %q, %v", v, ok)
+	}
+	if _, ok := b.Header("Missing"); ok {
+		t.Error("Header(Missing) reported present")
+	}
+	if NewBlock("line1\nline2", "email").Render() != WrapContent("line1\nline2", "email") {
+		t.Error("NewBlock().Render() differs from WrapContent")
+	}
+}
+
+func TestParseBlock_NoSource(t *testing.T) {
+	b := NewBlock("hello", "web")
+	b.NoSource = true
+	b.AddBlockID()
+	wrapped := b.Render()
+	if strings.Contains(wrapped, "Source:") {
+		t.Errorf("Render() kept the source line: %q", wrapped)
+	}
+
+	parsed, err := ParseBlock(wrapped)
+	if err != nil {
+		t.Fatalf("ParseBlock() error = %v", err)
+	}
+	if !parsed.NoSource || parsed.Source != "" {
+		t.Errorf("NoSource = %v, Source = %q, want true, empty", parsed.NoSource, parsed.Source)
+	}
+	if parsed.Content != "hello" || len(parsed.Headers) != 1 || parsed.Headers[0].Name != "Block-ID" {
+		t.Errorf("ParseBlock() = %+v", parsed)
+	}
+}
+
+func TestParseBlock_Malformed(t *testing.T) {
+	valid := WrapContent("hello", "web")
+
+	tests := []struct {
+		name       string
+		wrapped    string
+		wantOffset int
+	}{
+		{"empty", "", 0},
+		{"text before", "intro\n" + valid, 0},
+		{"trailing newline", valid + "\n", len(valid) + 1},
+		{"text after", valid + "\nmore", len(valid) + 5},
+		{"start marker only", StartMarker, len(StartMarker)},
+		{"missing source", StartMarker + "\nbody\n---\nx\n" + EndMarker, len(StartMarker) + 1},
+		{"no source or separator", StartMarker + "\nBlock-ID: abc\nx\n" + EndMarker, len(StartMarker) + 15},
+		{"missing separator", StartMarker + "\nSource: web\nx\n" + EndMarker, len(StartMarker) + 13},
+		{"no content line", StartMarker + "\nSource: web\n---\n" + EndMarker, len(StartMarker) + 16},
+		{"nonce mismatch", strings.Replace(valid, StartMarker, nonceMarker(StartMarker, "ab"), 1), len(valid) + 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBlock(tt.wrapped)
+			if !errors.Is(err, ErrUnwrapMalformed) {
+				t.Fatalf("ParseBlock() error = %v, want ErrUnwrapMalformed", err)
+			}
+			var malformed *MalformedError
+			if errors.As(err, &malformed) && malformed.Offset != tt.wantOffset {
+				t.Errorf("Offset = %d, want %d (%v)", malformed.Offset, tt.wantOffset, err)
+			}
+		})
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	content, source, err := Unwrap(WrapContentWithBlockID("a\n<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>\nb", "web"))
+	if err != nil {
+		t.Fatalf("Unwrap() error = %v", err)
+	}
+	if content != "a\n<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>\nb" || source != "web" {
+		t.Errorf("Unwrap() = %q, %q", content, source)
+	}
+
+	for _, bad := range []string{"", "plain text", WrapContent("x", "web") + "\n", StartMarker + "\nSource: web\nx\n" + EndMarker} {
+		if _, _, err := Unwrap(bad); !errors.Is(err, ErrUnwrapMalformed) {
+			t.Errorf("Unwrap(%q) error = %v, want ErrUnwrapMalformed", bad, err)
+		}
+	}
+}
+
+func TestUnwrap_MultilineSource(t *testing.T) {
+	// A raw line break in the source forges a separator, so the round trip breaks
+	source := "web\n---\nforged"
+	content, got, err := Unwrap(WrapContent("data", source))
+	if err != nil {
+		t.Fatalf("Unwrap() error = %v", err)
+	}
+	if content != "forged\n---\ndata" || got != "web" {
+		t.Errorf("Unwrap() = %q, %q, want the source cut at its line break", content, got)
+	}
+
+	// Sanitized first, the source stays on one line and round-trips
+	safe := SanitizeSource(source)
+	content, got, err = Unwrap(WrapContent("data", safe))
+	if err != nil {
+		t.Fatalf("Unwrap() error = %v", err)
+	}
+	if content != "data" || got != safe {
+		t.Errorf("Unwrap() = %q, %q, want %q, %q", content, got, "data", safe)
+	}
+}
+
+func TestSameContent_Length(t *testing.T) {
+	// Large contents sharing everything but their last byte: a length mismatch decides
+	// without a byte comparison, and equal lengths still compare every byte
+	body := strings.Repeat("shared prefix line\n", 1<<16)
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"one byte longer", body + "x", body + "xy", false},
+		{"one byte shorter", body + "xy", body + "x", false},
+		{"same length, last byte differs", body + "x", body + "y", false},
+		{"identical", body + "x", body + "x", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SameContent(WrapContent(tt.a, "a"), WrapContent(tt.b, "b"))
+			if err != nil || got != tt.want {
+				t.Errorf("SameContent() = %v, %v; want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSameContent(t *testing.T) {
+	unique, _, _, err := WrapContentUniqueBoundary("report", "mirror")
+	if err != nil {
+		t.Fatalf("WrapContentUniqueBoundary() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"same source", WrapContent("report", "web"), WrapContent("report", "web"), true},
+		{"different source", WrapContent("report", "web"), WrapContent("report", "email"), true},
+		{"headers ignored", WrapContentWithBlockID("report", "web"), WrapContentWithVia("report", "cache", "web"), true},
+		{"nonce markers ignored", unique, WrapContent("report", "web"), true},
+		{"different content", WrapContent("report", "web"), WrapContent("rep0rt", "web"), false},
+		{"different length", WrapContent("report", "web"), WrapContent("report\n", "web"), false},
+		{"empty", WrapContent("", "a"), WrapContent("", "b"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SameContent(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("SameContent() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SameContent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := SameContent("not wrapped", WrapContent("x", "y")); !errors.Is(err, ErrUnwrapMalformed) {
+		t.Errorf("SameContent(malformed, ...) error = %v, want ErrUnwrapMalformed", err)
+	}
+	if _, err := SameContent(WrapContent("x", "y"), "not wrapped"); !errors.Is(err, ErrUnwrapMalformed) {
+		t.Errorf("SameContent(..., malformed) error = %v, want ErrUnwrapMalformed", err)
+	}
+}
@@ -0,0 +1,31 @@
+package wrapper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// blockIDHeader names the header added by WrapContentWithBlockID
+const blockIDHeader = "Block-ID"
+
+// BlockID returns a stable identifier for a (content, source) pair: the hex SHA-256 of
+// source + "\x00" + content over the raw bytes. Identical pairs always share an ID, unlike
+// the random nonce of WrapContentUniqueBoundary. Source labels are expected not to contain
+// NUL bytes; if they do, the split point between source and content is ambiguous.
+func BlockID(content, source string) string {
+	h := sha256.New()
+	h.Write([]byte(source))
+	h.Write([]byte{0})
+	h.Write([]byte(content))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// WrapContentWithBlockID wraps content with a "Block-ID: <BlockID>" header after the source line
+func WrapContentWithBlockID(content, source string) string {
+	return wrapWithHeaders(content, source, blockIDHeader+": "+BlockID(content, source))
+}
+
+// AddBlockID appends a Block-ID header computed from the block's content and source
+func (b *Block) AddBlockID() {
+	b.Headers = append(b.Headers, Header{Name: blockIDHeader, Value: BlockID(b.Content, b.Source)})
+}
@@ -0,0 +1,82 @@
+package wrapper
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxBoundaryAttempts bounds how many nonces are tried before giving up
+const maxBoundaryAttempts = 8
+
+// randReader is the entropy source for nonces; tests replace it to force collisions
+var randReader io.Reader = rand.Reader
+
+// WrapContentUniqueBoundary wraps content with markers carrying a random nonce, e.g.
+// <<<EXTERNAL_UNTRUSTED_CONTENT:9f2c...>>>, that is verified not to occur anywhere in
+// the content. Because the content cannot contain the closing marker, it cannot close the
+// block early. The chosen markers are returned so they can be communicated to the model.
+func WrapContentUniqueBoundary(content, source string) (wrapped, start, end string, err error) {
+	for attempt := 0; attempt < maxBoundaryAttempts; attempt++ {
+		nonce, err := newNonce()
+		if err != nil {
+			return "", "", "", err
+		}
+		// Both suffixed markers end in ":<nonce>>>", so one scan rules out either
+		if strings.Contains(content, ":"+nonce+">>>") {
+			continue
+		}
+		start = nonceMarker(StartMarker, nonce)
+		end = nonceMarker(EndMarker, nonce)
+		return start + "\n" + SourcePrefix + source + "\n" + Separator + "\n" + content + "\n" + end, start, end, nil
+	}
+	return "", "", "", ErrNoUniqueBoundary
+}
+
+// WrapContentNonce wraps content with markers carrying a fresh random nonce, like
+// WrapContentUniqueBoundary, for callers that don't need the markers returned separately.
+// An attacker who knows the fixed markers still can't forge the closing one, since the
+// nonce is chosen after the content is known. The protection only holds if the model is
+// told the nonce out-of-band, typically by the system prompt: pass the parsed block to
+// SystemPrompt, or read the nonce back with UnwrapNonce. It panics only if the system's
+// secure random source fails.
+func WrapContentNonce(content, source string) string {
+	wrapped, _, _, err := WrapContentUniqueBoundary(content, source)
+	if err != nil {
+		panic(err)
+	}
+	return wrapped
+}
+
+// UnwrapNonce parses a block written by WrapContentNonce or WrapContentUniqueBoundary and
+// returns its content, source and nonce. The end marker must carry the start marker's
+// nonce, so a default or wrongly-suffixed end marker inside the content is just content.
+// A block with the plain default markers is rejected. Failures are *MalformedError values
+// matching ErrUnwrapMalformed.
+func UnwrapNonce(wrapped string) (content, source, nonce string, err error) {
+	b, err := ParseBlock(wrapped)
+	if err != nil {
+		return "", "", "", err
+	}
+	nonce = strings.TrimSuffix(strings.TrimPrefix(b.StartMarker, strings.TrimSuffix(StartMarker, ">>>")+":"), ">>>")
+	if b.StartMarker == StartMarker || nonce == "" {
+		return "", "", "", &MalformedError{Offset: 0, Reason: "start marker carries no nonce"}
+	}
+	return b.Content, b.Source, nonce, nil
+}
+
+// newNonce returns 16 hex characters from crypto/rand
+func newNonce() (string, error) {
+	b := make([]byte, 8)
+	if _, err := io.ReadFull(randReader, b); err != nil {
+		return "", fmt.Errorf("generating nonce: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// nonceMarker inserts a nonce before the closing >>> of a marker
+func nonceMarker(marker, nonce string) string {
+	return strings.TrimSuffix(marker, ">>>") + ":" + nonce + ">>>"
+}
@@ -0,0 +1,30 @@
+package wrapper
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// canaryHeader names the leak-detection header added by AddCanary
+const canaryHeader = "Canary"
+
+// canaryPrefix makes canaries easy to grep for and unlikely to occur in ordinary text
+const canaryPrefix = "ps-canary-"
+
+// NewCanary returns a crypto-random canary token such as ps-canary-3f9c…, 128 bits of
+// randomness in hex. Register it, then search model outputs and logs for it: finding it
+// means the block was repeated verbatim somewhere it should not have been.
+func NewCanary() (string, error) {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(randReader, b); err != nil {
+		return "", fmt.Errorf("generating canary: %w", err)
+	}
+	return canaryPrefix + hex.EncodeToString(b), nil
+}
+
+// AddCanary appends a Canary header. The canary is operator metadata; callers that need
+// reproducible output, such as golden tests, pass a fixed value instead of NewCanary.
+func (b *Block) AddCanary(canary string) {
+	b.Headers = append(b.Headers, Header{Name: canaryHeader, Value: canary})
+}
@@ -0,0 +1,51 @@
+package wrapper
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+)
+
+func TestNewCanary(t *testing.T) {
+	format := regexp.MustCompile(`^ps-canary-[0-9a-f]{32}$`)
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		canary, err := NewCanary()
+		if err != nil {
+			t.Fatalf("NewCanary() error = %v", err)
+		}
+		if !format.MatchString(canary) {
+			t.Errorf("NewCanary() = %q, bad format", canary)
+		}
+		if seen[canary] {
+			t.Errorf("NewCanary() repeated %q", canary)
+		}
+		seen[canary] = true
+	}
+}
+
+func TestNewCanary_EntropyFailure(t *testing.T) {
+	orig := randReader
+	defer func() { randReader = orig }()
+	randReader = bytes.NewReader(nil)
+
+	if _, err := NewCanary(); err == nil {
+		t.Error("Expected error when entropy is unavailable")
+	}
+}
+
+func TestBlock_AddCanary(t *testing.T) {
+	b := NewBlock("data", "web")
+	b.AddCanary("ps-canary-fixed")
+	want := "<<<EXTERNAL_UNTRUSTED_CONTENT>>>\nSource: web\nCanary: ps-canary-fixed\n---\ndata\n<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>"
+	if got := b.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	parsed, err := ParseBlock(want)
+	if err != nil {
+		t.Fatalf("ParseBlock() error = %v", err)
+	}
+	if v, _ := parsed.Header("Canary"); v != "ps-canary-fixed" {
+		t.Errorf("Canary header = %q", v)
+	}
+}
@@ -0,0 +1,61 @@
+package wrapper
+
+import "io"
+
+// ChunkWrapper wraps content that arrives in pieces, such as gRPC stream messages, without
+// buffering it. The header is written with the first chunk and the end marker by Finish, so
+// the bytes written to dst equal WrapContent of the concatenated chunks.
+// A ChunkWrapper is not safe for concurrent use.
+type ChunkWrapper struct {
+	dst      io.Writer
+	source   string
+	started  bool
+	finished bool
+	err      error
+}
+
+// NewChunkWrapper returns a ChunkWrapper writing to dst
+func NewChunkWrapper(dst io.Writer, source string) *ChunkWrapper {
+	return &ChunkWrapper{dst: dst, source: source}
+}
+
+// WriteChunk writes the header if needed, then the chunk. A write error is sticky.
+func (c *ChunkWrapper) WriteChunk(chunk []byte) error {
+	if c.err != nil {
+		return c.err
+	}
+	if c.finished {
+		return ErrFinished
+	}
+	if err := c.start(); err != nil {
+		return err
+	}
+	_, c.err = c.dst.Write(chunk)
+	return c.err
+}
+
+// Finish writes the closing marker, emitting a complete empty block if no chunk was written.
+// Calling Finish again is a no-op.
+func (c *ChunkWrapper) Finish() error {
+	if c.err != nil {
+		return c.err
+	}
+	if c.finished {
+		return nil
+	}
+	if err := c.start(); err != nil {
+		return err
+	}
+	c.finished = true
+	_, c.err = io.WriteString(c.dst, "\n"+EndMarker)
+	return c.err
+}
+
+func (c *ChunkWrapper) start() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+	_, c.err = io.WriteString(c.dst, StartMarker+"\n"+SourcePrefix+c.source+"\n"+Separator+"\n")
+	return c.err
+}
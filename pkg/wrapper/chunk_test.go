@@ -0,0 +1,92 @@
+package wrapper
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestChunkWrapper(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+	}{
+		{"zero chunks", nil},
+		{"single empty chunk", []string{""}},
+		{"single chunk", []string{"hello world"}},
+		{"many chunks", []string{"hel", "lo ", "wor", "ld\n"}},
+		{"marker split across chunks", []string{"<<<END_EXTERNAL_", "UNTRUSTED_CONTENT>>>"}},
+		{"binary chunks", []string{"\x00\xff", "\xfe\x00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			cw := NewChunkWrapper(&buf, "grpc")
+			for _, chunk := range tt.chunks {
+				if err := cw.WriteChunk([]byte(chunk)); err != nil {
+					t.Fatalf("WriteChunk() error = %v", err)
+				}
+			}
+			if err := cw.Finish(); err != nil {
+				t.Fatalf("Finish() error = %v", err)
+			}
+
+			want := WrapContent(strings.Join(tt.chunks, ""), "grpc")
+			if buf.String() != want {
+				t.Errorf("output = %q, want %q", buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestChunkWrapper_ByteAtATime(t *testing.T) {
+	content := "line one\nline two\n<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>\n日本語"
+	var buf bytes.Buffer
+	cw := NewChunkWrapper(&buf, "bytes")
+	for i := 0; i < len(content); i++ {
+		if err := cw.WriteChunk([]byte{content[i]}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cw.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != WrapContent(content, "bytes") {
+		t.Errorf("output differs from WrapContent: %q", buf.String())
+	}
+}
+
+func TestChunkWrapper_AfterFinish(t *testing.T) {
+	var buf bytes.Buffer
+	cw := NewChunkWrapper(&buf, "grpc")
+	if err := cw.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.Finish(); err != nil {
+		t.Errorf("Second Finish() error = %v", err)
+	}
+	if err := cw.WriteChunk([]byte("late")); !errors.Is(err, ErrFinished) {
+		t.Errorf("WriteChunk() after Finish error = %v, want ErrFinished", err)
+	}
+	if strings.Count(buf.String(), "<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>") != 1 {
+		t.Errorf("End marker written more than once: %q", buf.String())
+	}
+}
+
+func TestChunkWrapper_WriteError(t *testing.T) {
+	wantErr := errors.New("stream closed")
+	cw := NewChunkWrapper(failingWriter{wantErr}, "grpc")
+
+	if err := cw.WriteChunk([]byte("data")); !errors.Is(err, wantErr) {
+		t.Errorf("WriteChunk() error = %v, want %v", err, wantErr)
+	}
+	if err := cw.Finish(); !errors.Is(err, wantErr) {
+		t.Errorf("Finish() error = %v, want sticky %v", err, wantErr)
+	}
+}
+
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write(p []byte) (int, error) { return 0, w.err }
@@ -0,0 +1,37 @@
+package wrapper
+
+import "strings"
+
+// ContainmentReport reports, for each needle found in wrapped, whether every occurrence lies
+// fully inside the real block: after the first start marker line and before the last end
+// marker line, the same boundary Inspect uses. An occurrence before the block, after it, or
+// straddling a marker counts as leaked (false). Needles that do not occur at all, and the
+// empty needle, are left out of the map, so a payload mangled in transit is not mistaken for
+// a contained one.
+func ContainmentReport(wrapped string, needles []string) map[string]bool {
+	in := Inspect(wrapped)
+	inside := func(start, end int) bool {
+		return in.StartOffset >= 0 && in.EndOffset >= 0 &&
+			start >= in.StartOffset+len(in.StartMarker) && end <= in.EndOffset
+	}
+
+	report := make(map[string]bool)
+	for _, needle := range needles {
+		if needle == "" {
+			continue
+		}
+		for from := 0; ; {
+			i := strings.Index(wrapped[from:], needle)
+			if i == -1 {
+				break
+			}
+			i += from
+			contained := inside(i, i+len(needle))
+			if prev, seen := report[needle]; !seen || prev {
+				report[needle] = contained
+			}
+			from = i + 1
+		}
+	}
+	return report
+}
@@ -0,0 +1,28 @@
+package wrapper
+
+import "strings"
+
+// WrapDepth counts how many complete wrapper layers content already has. A layer counts only
+// when the content, ignoring trailing newlines, is exactly one block: a start marker line, a
+// valid header, and the matching end marker as the last line. Content that merely contains
+// markers, or has text around a block, has depth 0.
+func WrapDepth(content string) int {
+	depth := 0
+	for {
+		inner, ok := unwrapLayer(content)
+		if !ok {
+			return depth
+		}
+		depth++
+		content = inner
+	}
+}
+
+// unwrapLayer returns the content of s when s, ignoring trailing newlines, is a single block
+func unwrapLayer(s string) (string, bool) {
+	b, err := ParseBlock(strings.TrimRight(s, "\n"))
+	if err != nil {
+		return "", false
+	}
+	return b.Content, true
+}
@@ -0,0 +1,48 @@
+package wrapper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrapDepth(t *testing.T) {
+	once := WrapContent("payload", "web")
+	twice := WrapContent(once+"\n", "scraper")
+	thrice := WrapContent(twice, "summarizer")
+	unique, _, _, err := WrapContentUniqueBoundary(once, "nonce")
+	if err != nil {
+		t.Fatalf("WrapContentUniqueBoundary() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"plain", "payload", 0},
+		{"empty", "", 0},
+		{"one layer", once, 1},
+		{"one layer with CLI newline", once + "\n", 1},
+		{"two layers", twice, 2},
+		{"three layers", thrice, 3},
+		{"empty content layer", WrapContent("", "web"), 1},
+		{"block-id header", WrapContentWithBlockID(once, "web"), 2},
+		{"nonce markers", unique, 2},
+		{"mentions markers", "see <<<EXTERNAL_UNTRUSTED_CONTENT>>> and <<<END_EXTERNAL_UNTRUSTED_CONTENT>>>", 0},
+		{"block after text", "intro\n" + once, 0},
+		{"text after block", WrapContent("x", "web") + "\ntrailer", 0},
+		{"nested block mid-content", WrapContent("intro\n"+once, "web"), 1},
+		{"omitted source line", "<<<EXTERNAL_UNTRUSTED_CONTENT>>>\n---\nx\n<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>", 1},
+		{"missing header", "<<<EXTERNAL_UNTRUSTED_CONTENT>>>\nx\n<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>", 0},
+		{"legend line counts as text", WrapContentWithLegend("x", "web", ""), 0},
+		{"mismatched nonce", strings.Replace(unique, "<<<END_EXTERNAL_UNTRUSTED_CONTENT:", "<<<END_EXTERNAL_UNTRUSTED_CONTENT:0", 1), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WrapDepth(tt.content); got != tt.want {
+				t.Errorf("WrapDepth() = %d, want %d\ncontent: %q", got, tt.want, tt.content)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package wrapper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// diffOp is one line of an edit script: ' ' kept, '-' only in stored, '+' only in fresh
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// DiffAgainstWrapped parses storedWrapped with ParseBlock and compares its content with
+// freshContent line by line. When they differ, diff is a unified diff (--- stored,
+// +++ fresh, @@ hunks with three lines of context). Source and headers are not compared.
+func DiffAgainstWrapped(storedWrapped, freshContent string) (changed bool, diff string, err error) {
+	b, err := ParseBlock(storedWrapped)
+	if err != nil {
+		return false, "", err
+	}
+	if b.Content == freshContent {
+		return false, "", nil
+	}
+	return true, unifiedDiff(strings.Split(b.Content, "\n"), strings.Split(freshContent, "\n")), nil
+}
+
+// unifiedDiff renders the line diff of a and b in unified format
+func unifiedDiff(a, b []string) string {
+	ops := diffLines(a, b)
+
+	var sb strings.Builder
+	sb.WriteString("--- stored\n+++ fresh\n")
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// Extend the hunk while the next change is within two contexts' reach
+		start := max(i-diffContext, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContext {
+				end = min(end+diffContext, len(ops))
+				break
+			}
+			end = next
+		}
+		writeHunk(&sb, ops, start, end)
+		i = end
+	}
+	return sb.String()
+}
+
+// writeHunk writes ops[start:end] with its @@ header. Line numbers are 1-based; an empty
+// side starts at the line before the hunk, as in diff -u.
+func writeHunk(sb *strings.Builder, ops []diffOp, start, end int) {
+	aLine, bLine := 1, 1
+	for _, op := range ops[:start] {
+		if op.kind != '+' {
+			aLine++
+		}
+		if op.kind != '-' {
+			bLine++
+		}
+	}
+	var aCount, bCount int
+	for _, op := range ops[start:end] {
+		if op.kind != '+' {
+			aCount++
+		}
+		if op.kind != '-' {
+			bCount++
+		}
+	}
+	if aCount == 0 {
+		aLine--
+	}
+	if bCount == 0 {
+		bLine--
+	}
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", aLine, aCount, bLine, bCount)
+	for _, op := range ops[start:end] {
+		sb.WriteByte(op.kind)
+		sb.WriteString(op.line)
+		sb.WriteByte('\n')
+	}
+}
+
+// diffLines returns an edit script turning a into b, built from a longest common
+// subsequence after trimming the shared prefix and suffix. The LCS uses Hirschberg's
+// divide and conquer, so memory stays linear in the input instead of len(a)*len(b).
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	fwd, rev := make([]int, len(b)+1), make([]int, len(b)+1)
+	ops = diffMiddle(ops, a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], fwd, rev)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// diffMiddle appends the edit script for a to b. It splits a in half, finds where the
+// LCS crosses that split from one forward and one backward row, and recurses on both
+// sides. fwd and rev are scratch rows of at least len(b)+1 entries.
+func diffMiddle(ops []diffOp, a, b []string, fwd, rev []int) []diffOp {
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	case len(b) == 0:
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		return ops
+	case len(a) == 1:
+		for j, line := range b {
+			if line == a[0] {
+				for _, ins := range b[:j] {
+					ops = append(ops, diffOp{'+', ins})
+				}
+				ops = append(ops, diffOp{' ', line})
+				for _, ins := range b[j+1:] {
+					ops = append(ops, diffOp{'+', ins})
+				}
+				return ops
+			}
+		}
+		ops = append(ops, diffOp{'-', a[0]})
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+
+	mid := len(a) / 2
+	lcsRow(fwd, a[:mid], b, false)
+	lcsRow(rev, a[mid:], b, true)
+	// Split b where the LCS of the two halves is longest; the first such point keeps
+	// deletions ahead of insertions, as a table walk would
+	split, best := 0, -1
+	for k := 0; k <= len(b); k++ {
+		if n := fwd[k] + rev[len(b)-k]; n > best {
+			split, best = k, n
+		}
+	}
+	ops = diffMiddle(ops, a[:mid], b[:split], fwd, rev)
+	return diffMiddle(ops, a[mid:], b[split:], fwd, rev)
+}
+
+// lcsRow fills row[j] with the LCS length of a and the first j lines of b, or with
+// reversed a and b when backward is set, keeping a single row of state
+func lcsRow(row []int, a, b []string, backward bool) {
+	row = row[:len(b)+1]
+	clear(row)
+	for i := range a {
+		ai := a[i]
+		if backward {
+			ai = a[len(a)-1-i]
+		}
+		diag := 0
+		for j := 1; j <= len(b); j++ {
+			bj := b[j-1]
+			if backward {
+				bj = b[len(b)-j]
+			}
+			up := row[j]
+			if ai == bj {
+				row[j] = diag + 1
+			} else if row[j-1] > up {
+				row[j] = row[j-1]
+			}
+			diag = up
+		}
+	}
+}
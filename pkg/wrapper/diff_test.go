@@ -0,0 +1,173 @@
+package wrapper
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDiffAgainstWrapped_Unchanged(t *testing.T) {
+	stored := WrapContentWithBlockID("line one\nline two", "Web")
+	changed, diff, err := DiffAgainstWrapped(stored, "line one\nline two")
+	if err != nil {
+		t.Fatalf("DiffAgainstWrapped() error = %v", err)
+	}
+	if changed || diff != "" {
+		t.Errorf("DiffAgainstWrapped() = %v, %q, want false, \"\"", changed, diff)
+	}
+}
+
+func TestDiffAgainstWrapped_Changed(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored string
+		fresh  string
+		want   string
+	}{
+		{
+			name:   "replaced line",
+			stored: "a\nb\nc",
+			fresh:  "a\nB\nc",
+			want:   "--- stored\n+++ fresh\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:   "appended line",
+			stored: "a",
+			fresh:  "a\nb",
+			want:   "--- stored\n+++ fresh\n@@ -1,1 +1,2 @@\n a\n+b\n",
+		},
+		{
+			name:   "everything removed",
+			stored: "a\nb",
+			fresh:  "",
+			want:   "--- stored\n+++ fresh\n@@ -1,2 +1,1 @@\n-a\n-b\n+\n",
+		},
+		{
+			name:   "distant changes get separate hunks",
+			stored: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10",
+			fresh:  "one\n2\n3\n4\n5\n6\n7\n8\n9\nten",
+			want: "--- stored\n+++ fresh\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{
+			name:   "nearby changes share a hunk",
+			stored: "1\n2\n3\n4\n5",
+			fresh:  "one\n2\n3\n4\nfive",
+			want:   "--- stored\n+++ fresh\n@@ -1,5 +1,5 @@\n-1\n+one\n 2\n 3\n 4\n-5\n+five\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed, diff, err := DiffAgainstWrapped(WrapContent(tt.stored, "Web"), tt.fresh)
+			if err != nil {
+				t.Fatalf("DiffAgainstWrapped() error = %v", err)
+			}
+			if !changed {
+				t.Error("changed = false, want true")
+			}
+			if diff != tt.want {
+				t.Errorf("diff =\n%s\nwant\n%s", diff, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffAgainstWrapped_InsertionDeletion(t *testing.T) {
+	_, diff, err := DiffAgainstWrapped(WrapContent("keep\ndrop\nkeep too", "Web"), "keep\nkeep too\nnew")
+	if err != nil {
+		t.Fatalf("DiffAgainstWrapped() error = %v", err)
+	}
+	for _, want := range []string{"-drop\n", "+new\n", " keep too\n"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+}
+
+func TestDiffAgainstWrapped_Malformed(t *testing.T) {
+	_, _, err := DiffAgainstWrapped("not a block", "x")
+	if !errors.Is(err, ErrUnwrapMalformed) {
+		t.Errorf("error = %v, want ErrUnwrapMalformed", err)
+	}
+}
+
+func TestDiffLines_MinimalScript(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	lines := func() []string {
+		out := make([]string, rng.Intn(12))
+		for i := range out {
+			out[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return out
+	}
+	for n := 0; n < 500; n++ {
+		a, b := lines(), lines()
+		var gotA, gotB []string
+		kept := 0
+		for _, op := range diffLines(a, b) {
+			if op.kind != '+' {
+				gotA = append(gotA, op.line)
+			}
+			if op.kind != '-' {
+				gotB = append(gotB, op.line)
+			}
+			if op.kind == ' ' {
+				kept++
+			}
+		}
+		if strings.Join(gotA, ",") != strings.Join(a, ",") || strings.Join(gotB, ",") != strings.Join(b, ",") {
+			t.Fatalf("diffLines(%q, %q) does not rebuild both sides", a, b)
+		}
+		// Reference LCS length from the full table, fine at this size
+		lcs := make([][]int, len(a)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		if kept != lcs[0][0] {
+			t.Fatalf("diffLines(%q, %q) kept %d lines, want %d", a, b, kept, lcs[0][0])
+		}
+	}
+}
+
+func TestDiffAgainstWrapped_LargeInputMemory(t *testing.T) {
+	const n = 8000
+	stored, fresh := make([]string, n), make([]string, n)
+	for i := range stored {
+		// Every other line differs, so trimming the shared ends saves nothing
+		stored[i] = fmt.Sprintf("line %d", i)
+		fresh[i] = stored[i]
+		if i%2 == 0 {
+			fresh[i] = fmt.Sprintf("changed %d", i)
+		}
+	}
+	wrapped := WrapContent(strings.Join(stored, "\n"), "Web")
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	changed, diff, err := DiffAgainstWrapped(wrapped, strings.Join(fresh, "\n"))
+	runtime.ReadMemStats(&after)
+	if err != nil || !changed {
+		t.Fatalf("DiffAgainstWrapped() = %v, _, %v, want true, nil", changed, err)
+	}
+	if !strings.Contains(diff, "-line 0\n+changed 0\n") {
+		t.Errorf("diff missing first change:\n%.200s", diff)
+	}
+	// A full LCS table would be n*n ints (about 500 MB); linear space stays far below
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 32<<20 {
+		t.Errorf("DiffAgainstWrapped allocated %d bytes, want at most %d", alloc, 32<<20)
+	}
+}
@@ -0,0 +1,38 @@
+package wrapper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// digestHeader names the integrity header added by WrapContentWithDigest
+const digestHeader = "SHA256"
+
+// contentDigest returns the lowercase hex SHA-256 of the raw content bytes
+func contentDigest(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// WrapContentWithDigest wraps content with a "SHA256: <hex>" header after the source line.
+// The digest covers the raw content bytes only, not the source or the wrapped form, so it
+// is the same for binary content however the block is later re-wrapped.
+func WrapContentWithDigest(content, source string) string {
+	return Wrap(content, source, WithDigest())
+}
+
+// VerifyDigest parses wrapped as a single block and reports whether its content still
+// matches the first SHA256 header. It fails with ErrNoDigest when there is no such header,
+// and with a *MalformedError when wrapped is not a block. The hex comparison ignores case.
+func VerifyDigest(wrapped string) (bool, error) {
+	b, err := ParseBlock(wrapped)
+	if err != nil {
+		return false, err
+	}
+	want, ok := b.Header(digestHeader)
+	if !ok {
+		return false, ErrNoDigest
+	}
+	return strings.EqualFold(want, contentDigest(b.Content)), nil
+}
@@ -0,0 +1,73 @@
+package wrapper
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWrapContentWithDigest(t *testing.T) {
+	wrapped := WrapContentWithDigest("hello", "web")
+	lines := strings.Split(wrapped, "\n")
+	want := "SHA256: 2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if lines[1] != "Source: web" || lines[2] != want || lines[3] != "---" {
+		t.Errorf("header = %q, want %q between source and separator", lines[:4], want)
+	}
+
+	// The digest depends only on the content
+	a := strings.Split(WrapContentWithDigest("same", "one"), "\n")[2]
+	b := strings.Split(WrapContentWithDigest("same", "two"), "\n")[2]
+	if a != b {
+		t.Errorf("digest changed with the source: %q vs %q", a, b)
+	}
+}
+
+func TestVerifyDigest(t *testing.T) {
+	contents := []string{
+		"",
+		"hello world",
+		"multi\nline\n\ncontent\n",
+		"binary \x00\x01\xff\xfe\x80",
+		"日本語 \U0001F600",
+	}
+
+	for _, content := range contents {
+		wrapped := WrapContentWithDigest(content, "tool")
+		ok, err := VerifyDigest(wrapped)
+		if err != nil || !ok {
+			t.Errorf("VerifyDigest(%q) = %v, %v; want true", content, ok, err)
+		}
+
+		if content == "" {
+			continue
+		}
+		// Flip one bit in the last content byte
+		i := strings.LastIndex(wrapped, "\n"+EndMarker) - 1
+		tampered := wrapped[:i] + string(wrapped[i]^0x01) + wrapped[i+1:]
+		ok, err = VerifyDigest(tampered)
+		if err != nil || ok {
+			t.Errorf("VerifyDigest(tampered %q) = %v, %v; want false", content, ok, err)
+		}
+	}
+}
+
+func TestVerifyDigest_Header(t *testing.T) {
+	wrapped := WrapContentWithDigest("data", "web")
+
+	upper := strings.Replace(wrapped, contentDigest("data"), strings.ToUpper(contentDigest("data")), 1)
+	if ok, err := VerifyDigest(upper); err != nil || !ok {
+		t.Errorf("VerifyDigest(uppercase hex) = %v, %v; want true", ok, err)
+	}
+
+	forged := strings.Replace(wrapped, contentDigest("data"), contentDigest("other"), 1)
+	if ok, err := VerifyDigest(forged); err != nil || ok {
+		t.Errorf("VerifyDigest(forged digest) = %v, %v; want false", ok, err)
+	}
+
+	if _, err := VerifyDigest(WrapContent("data", "web")); !errors.Is(err, ErrNoDigest) {
+		t.Errorf("VerifyDigest(no header) error = %v, want ErrNoDigest", err)
+	}
+	if _, err := VerifyDigest("not a block"); !errors.Is(err, ErrUnwrapMalformed) {
+		t.Errorf("VerifyDigest(garbage) error = %v, want ErrUnwrapMalformed", err)
+	}
+}
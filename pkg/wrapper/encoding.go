@@ -0,0 +1,50 @@
+package wrapper
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// contentEncodingHeader names the header recording how a block's content was encoded
+const contentEncodingHeader = "Content-Encoding"
+
+// Base64Encoding is the Content-Encoding value for standard, padded base64
+const Base64Encoding = "base64"
+
+// WrapBase64 wraps the base64 encoding of content with a "Content-Encoding: base64" header,
+// keeping binary bytes out of terminals and logs. DecodedContent on the parsed block
+// returns content exactly.
+func WrapBase64(content []byte, source string) string {
+	b := NewBlock("", source)
+	b.setBase64(content)
+	return b.Render()
+}
+
+// EncodeBase64 replaces b's content with its base64 encoding and records that in a
+// Content-Encoding header
+func (b *Block) EncodeBase64() {
+	b.setBase64([]byte(b.Content))
+}
+
+func (b *Block) setBase64(content []byte) {
+	b.Content = base64.StdEncoding.EncodeToString(content)
+	b.Headers = append(b.Headers, Header{Name: contentEncodingHeader, Value: Base64Encoding})
+}
+
+// DecodedContent returns b's content as bytes, decoding it when b has a Content-Encoding
+// header. Without one the content is returned as is. An unknown encoding or content that
+// doesn't decode is an error.
+func (b *Block) DecodedContent() ([]byte, error) {
+	enc, ok := b.Header(contentEncodingHeader)
+	if !ok {
+		return []byte(b.Content), nil
+	}
+	if enc != Base64Encoding {
+		return nil, fmt.Errorf("unknown Content-Encoding %q", enc)
+	}
+	data, err := base64.StdEncoding.DecodeString(b.Content)
+	if err != nil {
+		return nil, fmt.Errorf("decoding base64 content: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,77 @@
+package wrapper
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWrapBase64(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"PNG header", []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}},
+		{"PDF header", []byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")},
+		{"empty", nil},
+		{"markers", []byte("<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := WrapBase64(tt.data, "upload")
+			if !strings.Contains(wrapped, "\nContent-Encoding: base64\n---\n") {
+				t.Errorf("missing Content-Encoding header: %q", wrapped)
+			}
+			if strings.ContainsAny(wrapped, "\x00\r\x1a\x89") || strings.Count(wrapped, "<<<") != 2 {
+				t.Errorf("raw bytes leaked into the block: %q", wrapped)
+			}
+
+			b, err := ParseBlock(wrapped)
+			if err != nil {
+				t.Fatalf("ParseBlock() error = %v", err)
+			}
+			got, err := b.DecodedContent()
+			if err != nil {
+				t.Fatalf("DecodedContent() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.data) {
+				t.Errorf("DecodedContent() = %q, want %q", got, tt.data)
+			}
+		})
+	}
+}
+
+func TestBlock_EncodeBase64(t *testing.T) {
+	b := NewBlock("binary\x00data", "file")
+	b.AddBlockID()
+	b.EncodeBase64()
+	if b.Content != "YmluYXJ5AGRhdGE=" {
+		t.Errorf("Content = %q, want base64", b.Content)
+	}
+	if len(b.Headers) != 2 || b.Headers[0].Name != "Block-ID" || b.Headers[1] != (Header{"Content-Encoding", "base64"}) {
+		t.Errorf("Headers = %+v, want Content-Encoding after Block-ID", b.Headers)
+	}
+	if got, err := b.DecodedContent(); err != nil || string(got) != "binary\x00data" {
+		t.Errorf("DecodedContent() = %q, %v", got, err)
+	}
+}
+
+func TestBlock_DecodedContent(t *testing.T) {
+	plain := NewBlock("as is", "web")
+	if got, err := plain.DecodedContent(); err != nil || string(got) != "as is" {
+		t.Errorf("DecodedContent(plain) = %q, %v", got, err)
+	}
+
+	bad := NewBlock("not base64!", "web")
+	bad.Headers = []Header{{Name: "Content-Encoding", Value: "base64"}}
+	if _, err := bad.DecodedContent(); err == nil {
+		t.Error("DecodedContent(invalid base64) should fail")
+	}
+
+	unknown := NewBlock("x", "web")
+	unknown.Headers = []Header{{Name: "Content-Encoding", Value: "gzip"}}
+	if _, err := unknown.DecodedContent(); err == nil || !strings.Contains(err.Error(), "gzip") {
+		t.Errorf("DecodedContent(gzip) error = %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package wrapper
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors returned by this package; compare with errors.Is
+var (
+	// ErrNoUniqueBoundary is returned when no nonce absent from the content could be found
+	ErrNoUniqueBoundary = errors.New("no unique boundary found")
+
+	// ErrFinished is returned when a chunk is written after Finish
+	ErrFinished = errors.New("wrapper already finished")
+
+	// ErrUnknownTransform is returned when a pipeline names a transform that does not exist
+	ErrUnknownTransform = errors.New("unknown transform")
+
+	// ErrUnwrapMalformed is returned when wrapped text does not parse as a block
+	ErrUnwrapMalformed = errors.New("malformed wrapped block")
+
+	// ErrMissingToolCallID is returned when a tool result has no tool call to answer
+	ErrMissingToolCallID = errors.New("missing tool call ID")
+
+	// ErrPlaceholderNotFound is returned when a template does not contain the placeholder
+	ErrPlaceholderNotFound = errors.New("placeholder not found")
+
+	// ErrAmbiguousPlaceholder is returned when a placeholder occurs more than once
+	ErrAmbiguousPlaceholder = errors.New("placeholder occurs more than once")
+
+	// ErrMalformedFrame is returned when a binary frame is truncated, corrupt or unsupported
+	ErrMalformedFrame = errors.New("malformed frame")
+
+	// ErrNoDigest is returned when verifying a block that has no SHA256 header
+	ErrNoDigest = errors.New("block has no SHA256 header")
+
+	// ErrInvalidUTF8 is returned when content that must be UTF-8 is not
+	ErrInvalidUTF8 = errors.New("invalid UTF-8")
+
+	// ErrLimitExceeded is returned when input is over a size limit; more specific limit
+	// errors such as ErrResponseTooLarge match it too
+	ErrLimitExceeded = errors.New("limit exceeded")
+
+	// ErrResponseTooLarge is returned when an HTTP response body exceeds the read limit
+	ErrResponseTooLarge = fmt.Errorf("response body too large: %w", ErrLimitExceeded)
+
+	// ErrInvalidFormat is returned when a Wrapper's markers, prefix or separator are unusable
+	ErrInvalidFormat = errors.New("invalid wrapper format")
+
+	// ErrEqualMarkers is returned, wrapped in an ErrInvalidFormat error, when a Wrapper's
+	// start and end markers are the same
+	ErrEqualMarkers = errors.New("start and end markers are identical")
+)
+
+// MalformedError describes where and why wrapped text failed to parse. It matches
+// ErrUnwrapMalformed under errors.Is.
+type MalformedError struct {
+	Offset int    // byte offset in the wrapped text where parsing stopped
+	Reason string // what was expected there
+}
+
+func (e *MalformedError) Error() string {
+	return fmt.Sprintf("%v at offset %d: %s", ErrUnwrapMalformed, e.Offset, e.Reason)
+}
+
+func (e *MalformedError) Unwrap() error {
+	return ErrUnwrapMalformed
+}
+
+// InvalidUTF8Error gives the byte offset of the first invalid UTF-8 sequence in content. It
+// matches ErrInvalidUTF8 under errors.Is.
+type InvalidUTF8Error struct {
+	Offset int
+}
+
+func (e *InvalidUTF8Error) Error() string {
+	return fmt.Sprintf("%v at offset %d", ErrInvalidUTF8, e.Offset)
+}
+
+func (e *InvalidUTF8Error) Unwrap() error {
+	return ErrInvalidUTF8
+}
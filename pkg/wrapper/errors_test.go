@@ -0,0 +1,77 @@
+package wrapper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMalformedError(t *testing.T) {
+	var err error = fmt.Errorf("reading block: %w", &MalformedError{Offset: 42, Reason: "missing separator line"})
+
+	if !errors.Is(err, ErrUnwrapMalformed) {
+		t.Errorf("errors.Is(%v, ErrUnwrapMalformed) = false", err)
+	}
+
+	var malformed *MalformedError
+	if !errors.As(err, &malformed) {
+		t.Fatalf("errors.As(%v, *MalformedError) = false", err)
+	}
+	if malformed.Offset != 42 || malformed.Reason != "missing separator line" {
+		t.Errorf("MalformedError = %+v", malformed)
+	}
+
+	want := "reading block: malformed wrapped block at offset 42: missing separator line"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestSentinelErrors_Distinct(t *testing.T) {
+	sentinels := []error{ErrNoUniqueBoundary, ErrFinished, ErrUnknownTransform, ErrUnwrapMalformed}
+	for i, a := range sentinels {
+		for j, b := range sentinels {
+			if i != j && errors.Is(a, b) {
+				t.Errorf("errors.Is(%v, %v) = true", a, b)
+			}
+		}
+	}
+
+	// Errors returned by the package match their sentinels
+	cw := NewChunkWrapper(&bytes.Buffer{}, "test")
+	cw.Finish()
+	if err := cw.WriteChunk([]byte("late")); !errors.Is(err, ErrFinished) {
+		t.Errorf("WriteChunk after Finish = %v, want ErrFinished", err)
+	}
+}
+
+func TestErrEqualMarkers(t *testing.T) {
+	_, err := NewWrapperWithMarkers("<<<SAME>>>", "<<<SAME>>>")
+	if !errors.Is(err, ErrEqualMarkers) || !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("NewWrapperWithMarkers(same, same) error = %v, want ErrEqualMarkers and ErrInvalidFormat", err)
+	}
+	if _, err := WrapE("x", "web", WithMarkers("SAME", "SAME")); !errors.Is(err, ErrEqualMarkers) {
+		t.Errorf("WrapE(WithMarkers(same, same)) error = %v, want ErrEqualMarkers", err)
+	}
+
+	// Other format errors are not equal-marker errors
+	_, err = NewWrapperWithMarkers("", "<<<END>>>")
+	if !errors.Is(err, ErrInvalidFormat) || errors.Is(err, ErrEqualMarkers) {
+		t.Errorf("NewWrapperWithMarkers(empty, end) error = %v, want ErrInvalidFormat only", err)
+	}
+}
+
+func TestErrLimitExceeded(t *testing.T) {
+	resp := &http.Response{Body: io.NopCloser(strings.NewReader("too long")), Request: &http.Request{}}
+	_, err := WrapResponseLimit(resp, "web", 3)
+	if !errors.Is(err, ErrLimitExceeded) || !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("WrapResponseLimit() error = %v, want ErrLimitExceeded and ErrResponseTooLarge", err)
+	}
+	if errors.Is(ErrLimitExceeded, ErrResponseTooLarge) {
+		t.Error("ErrLimitExceeded matches the narrower ErrResponseTooLarge")
+	}
+}
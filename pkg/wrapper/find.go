@@ -0,0 +1,34 @@
+package wrapper
+
+// FindBlocks returns every well-formed block in doc, such as an assembled prompt, in order.
+// Each block's Start and End give its byte span, so doc[b.Start:b.End] == b.Render().
+//
+// Blocks are paired the way a first-match parser reads them: a start marker line opens a
+// block and the next line holding the matching end marker, nonce included, closes it. A
+// start marker line inside an open block supersedes the earlier one, which is left
+// unmatched, as are a start marker that is never closed and a stray end marker line. Pairs
+// whose interior doesn't parse, lacking a separator say, are skipped too.
+func FindBlocks(doc string) []Block {
+	var blocks []Block
+	open := -1 // offset of the current block's start marker, or -1
+	var wantEnd string
+
+	for _, l := range splitLines(doc) {
+		if isStartMarker(l.text) {
+			open = l.offset
+			wantEnd = endMarkerFor(l.text)
+			continue
+		}
+		if open < 0 || l.text != wantEnd {
+			continue
+		}
+
+		end := l.offset + len(l.text)
+		if b, err := ParseBlock(doc[open:end]); err == nil {
+			b.Start, b.End = open, end
+			blocks = append(blocks, *b)
+		}
+		open = -1
+	}
+	return blocks
+}
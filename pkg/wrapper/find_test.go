@@ -0,0 +1,62 @@
+package wrapper
+
+import "testing"
+
+func TestFindBlocks(t *testing.T) {
+	web := WrapContent("page text", "web")
+	tool := WrapContent("line one\nline two", "tool")
+	nonced := StartMarker[:len(StartMarker)-3] + ":abc>>>\nSource: mail\n---\nhi\n" + EndMarker[:len(EndMarker)-3] + ":abc>>>"
+
+	type span struct {
+		source, content string
+		start, end      int
+	}
+	tests := []struct {
+		name string
+		doc  string
+		want []span
+	}{
+		{"no blocks", "just a system prompt\nwith two lines", nil},
+		{"empty", "", nil},
+		{"one block alone", web, []span{{"web", "page text", 0, len(web)}}},
+		{"one block in text", "System: be careful\n" + web + "\nUser: summarize", []span{
+			{"web", "page text", 19, 19 + len(web)},
+		}},
+		{"multiple blocks", "A\n" + web + "\nB\n" + tool + "\n" + nonced, []span{
+			{"web", "page text", 2, 2 + len(web)},
+			{"tool", "line one\nline two", 5 + len(web), 5 + len(web) + len(tool)},
+			{"mail", "hi", 6 + len(web) + len(tool), 6 + len(web) + len(tool) + len(nonced)},
+		}},
+		{"dangling start marker", StartMarker + "\nnever closed\n" + web, []span{
+			{"web", "page text", len(StartMarker) + 14, len(StartMarker) + 14 + len(web)},
+		}},
+		{"dangling start marker at end", web + "\n" + StartMarker + "\nSource: x\n---\ncut off", []span{
+			{"web", "page text", 0, len(web)},
+		}},
+		{"stray end marker", EndMarker + "\n" + web, []span{
+			{"web", "page text", len(EndMarker) + 1, len(EndMarker) + 1 + len(web)},
+		}},
+		{"nonce must match", StartMarker[:len(StartMarker)-3] + ":abc>>>\nSource: x\n---\nhi\n" + EndMarker[:len(EndMarker)-3] + ":xyz>>>", nil},
+		{"malformed pair skipped", StartMarker + "\nno separator\n" + EndMarker + "\n" + tool, []span{
+			{"tool", "line one\nline two", len(StartMarker) + len(EndMarker) + 15, len(StartMarker) + len(EndMarker) + 15 + len(tool)},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindBlocks(tt.doc)
+			if len(got) != len(tt.want) {
+				t.Fatalf("FindBlocks() found %d blocks, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, w := range tt.want {
+				b := got[i]
+				if b.Source != w.source || b.Content != w.content || b.Start != w.start || b.End != w.end {
+					t.Errorf("block %d = {%q %q %d %d}, want %+v", i, b.Source, b.Content, b.Start, b.End, w)
+				}
+				if span := tt.doc[b.Start:b.End]; span != b.Render() {
+					t.Errorf("block %d span %q does not render back", i, span)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package wrapper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// Binary frame layout, all integers big-endian:
+//
+//	offset  size  field
+//	0       4     magic "PSFR"
+//	4       1     version, currently 1
+//	5       4     source length S
+//	9       S     source bytes
+//	9+S     4     content length C
+//	13+S    C     content bytes
+//	13+S+C  4     CRC-32 (IEEE) of every preceding byte of the frame
+//
+// Lengths are explicit, so content may hold markers, NUL or any other bytes without escaping.
+const (
+	frameMagic   = "PSFR"
+	frameVersion = 1
+)
+
+// WriteFrame writes content and source to w as a single binary frame. Fields longer than
+// 4 GiB - 1 cannot be framed; they fail with an error matching ErrLimitExceeded.
+func WriteFrame(w io.Writer, content, source string) error {
+	if uint64(len(source)) > math.MaxUint32 || uint64(len(content)) > math.MaxUint32 {
+		return fmt.Errorf("%w: %w: field too long for a frame", ErrMalformedFrame, ErrLimitExceeded)
+	}
+
+	crc := crc32.NewIEEE()
+	bw := bufio.NewWriter(io.MultiWriter(w, crc))
+	bw.WriteString(frameMagic)
+	bw.WriteByte(frameVersion)
+	binary.Write(bw, binary.BigEndian, uint32(len(source)))
+	bw.WriteString(source)
+	binary.Write(bw, binary.BigEndian, uint32(len(content)))
+	bw.WriteString(content)
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	// The checksum goes straight to w so it doesn't cover itself
+	return binary.Write(w, binary.BigEndian, crc.Sum32())
+}
+
+// ReadFrame reads one frame written by WriteFrame. It returns io.EOF when r ends cleanly
+// before a frame starts, so a stream of frames can be read in a loop. A bad magic, an
+// unknown version, a truncated frame or a checksum mismatch returns an error matching
+// ErrMalformedFrame. Field memory grows with the bytes actually read, so a forged length
+// can't force a huge allocation up front.
+func ReadFrame(r io.Reader) (content, source string, err error) {
+	crc := crc32.NewIEEE()
+	tr := io.TeeReader(r, crc)
+
+	var header [len(frameMagic) + 1]byte
+	if _, err := io.ReadFull(tr, header[:]); err == io.EOF {
+		return "", "", io.EOF
+	} else if err != nil {
+		return "", "", frameReadError("header", err)
+	}
+	if string(header[:len(frameMagic)]) != frameMagic {
+		return "", "", fmt.Errorf("%w: bad magic %q", ErrMalformedFrame, header[:len(frameMagic)])
+	}
+	if v := header[len(frameMagic)]; v != frameVersion {
+		return "", "", fmt.Errorf("%w: unsupported version %d", ErrMalformedFrame, v)
+	}
+
+	if source, err = readFrameString(tr, "source"); err != nil {
+		return "", "", err
+	}
+	if content, err = readFrameString(tr, "content"); err != nil {
+		return "", "", err
+	}
+
+	want := crc.Sum32()
+	var got uint32
+	if err := binary.Read(r, binary.BigEndian, &got); err != nil {
+		return "", "", frameReadError("checksum", err)
+	}
+	if got != want {
+		return "", "", fmt.Errorf("%w: checksum %08x, want %08x", ErrMalformedFrame, got, want)
+	}
+	return content, source, nil
+}
+
+// readFrameString reads a length-prefixed field
+func readFrameString(r io.Reader, field string) (string, error) {
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return "", frameReadError(field+" length", err)
+	}
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, int64(n)); err != nil {
+		return "", frameReadError(field, err)
+	}
+	return buf.String(), nil
+}
+
+// frameReadError reports a failed read of field, treating any EOF as truncation
+func frameReadError(field string, err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: truncated %s", ErrMalformedFrame, field)
+	}
+	return fmt.Errorf("reading frame %s: %w", field, err)
+}
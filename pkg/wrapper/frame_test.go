@@ -0,0 +1,119 @@
+package wrapper
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestFrame_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		source  string
+	}{
+		{"plain", "hello", "web"},
+		{"empty", "", ""},
+		{"markers and control bytes", WrapContent("x\x00\r\n<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>", "a") + "\xff\xfe", "src\nwith newline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteFrame(&buf, tt.content, tt.source); err != nil {
+				t.Fatalf("WriteFrame() error = %v", err)
+			}
+			if want := 17 + len(tt.source) + len(tt.content); buf.Len() != want {
+				t.Errorf("Frame is %d bytes, want %d", buf.Len(), want)
+			}
+			content, source, err := ReadFrame(&buf)
+			if err != nil {
+				t.Fatalf("ReadFrame() error = %v", err)
+			}
+			if content != tt.content || source != tt.source {
+				t.Errorf("ReadFrame() = %q, %q, want %q, %q", content, source, tt.content, tt.source)
+			}
+		})
+	}
+}
+
+func TestFrame_Layout(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteFrame(&buf, "hi", "s"); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{'P', 'S', 'F', 'R', 1, 0, 0, 0, 1, 's', 0, 0, 0, 2, 'h', 'i'}
+	got := buf.Bytes()
+	if !bytes.Equal(got[:len(want)], want) {
+		t.Errorf("Frame prefix = %v, want %v", got[:len(want)], want)
+	}
+	if crc := binary.BigEndian.Uint32(got[len(want):]); crc == 0 {
+		t.Error("Checksum is zero")
+	}
+}
+
+func TestFrame_Stream(t *testing.T) {
+	var buf bytes.Buffer
+	for _, c := range []string{"one", "two"} {
+		if err := WriteFrame(&buf, c, "web"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, want := range []string{"one", "two"} {
+		content, _, err := ReadFrame(&buf)
+		if err != nil || content != want {
+			t.Fatalf("ReadFrame() = %q, %v, want %q", content, err, want)
+		}
+	}
+	if _, _, err := ReadFrame(&buf); err != io.EOF {
+		t.Errorf("ReadFrame() at end = %v, want io.EOF", err)
+	}
+}
+
+func TestReadFrame_Malformed(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteFrame(&buf, "content", "source"); err != nil {
+		t.Fatal(err)
+	}
+	valid := buf.Bytes()
+
+	corrupt := func(i int) []byte {
+		b := bytes.Clone(valid)
+		b[i] ^= 0xff
+		return b
+	}
+	hugeLength := bytes.Clone(valid[:9])
+	binary.BigEndian.PutUint32(hugeLength[5:], 1<<31)
+
+	tests := []struct {
+		name  string
+		frame []byte
+	}{
+		{"bad magic", corrupt(0)},
+		{"bad version", corrupt(4)},
+		{"flipped content byte", corrupt(len(valid) - 5)},
+		{"flipped checksum", corrupt(len(valid) - 1)},
+		{"truncated header", valid[:3]},
+		{"truncated content", valid[:len(valid)-6]},
+		{"missing checksum", valid[:len(valid)-4]},
+		{"forged length", hugeLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ReadFrame(bytes.NewReader(tt.frame))
+			if !errors.Is(err, ErrMalformedFrame) {
+				t.Errorf("ReadFrame() error = %v, want ErrMalformedFrame", err)
+			}
+		})
+	}
+}
+
+func TestWriteFrame_WriteError(t *testing.T) {
+	wantErr := errors.New("disk full")
+	if err := WriteFrame(failingWriter{wantErr}, "x", "y"); !errors.Is(err, wantErr) {
+		t.Errorf("WriteFrame() error = %v, want %v", err, wantErr)
+	}
+}
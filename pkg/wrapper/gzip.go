@@ -0,0 +1,22 @@
+package wrapper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+)
+
+// WrapContentGzip returns WrapContent(content, source) gzip-compressed. Decompressing the
+// result gives back exactly the wrapped text; the trailing newline the CLI prints is not
+// included.
+func WrapContentGzip(content, source string) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(WrapContent(content, source))); err != nil {
+		return nil, fmt.Errorf("compressing wrapped content: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("compressing wrapped content: %w", err)
+	}
+	return buf.Bytes(), nil
+}
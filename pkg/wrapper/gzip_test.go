@@ -0,0 +1,32 @@
+package wrapper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWrapContentGzip(t *testing.T) {
+	for _, content := range []string{"", "hello", strings.Repeat("repetitive line\n", 1000)} {
+		compressed, err := WrapContentGzip(content, "web")
+		if err != nil {
+			t.Fatalf("WrapContentGzip() error = %v", err)
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			t.Fatalf("gzip.NewReader() error = %v", err)
+		}
+		got, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("decompressing: %v", err)
+		}
+		if want := WrapContent(content, "web"); string(got) != want {
+			t.Errorf("Decompressed %q, want %q", got, want)
+		}
+		if len(content) > 1000 && len(compressed) >= len(content)/10 {
+			t.Errorf("Repetitive content compressed to %d bytes from %d", len(compressed), len(content))
+		}
+	}
+}
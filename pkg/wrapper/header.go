@@ -0,0 +1,33 @@
+package wrapper
+
+import "strings"
+
+// wrapWithHeaders wraps content like WrapContent, adding "Name: value" header lines between
+// the source line and the separator. Header values are operator metadata and must be
+// single-line.
+func wrapWithHeaders(content, source string, headers ...string) string {
+	var b strings.Builder
+	b.WriteString(StartMarker + "\n" + SourcePrefix + source + "\n")
+	for _, h := range headers {
+		b.WriteString(h)
+		b.WriteByte('\n')
+	}
+	b.WriteString(Separator + "\n" + content + "\n" + EndMarker)
+	return b.String()
+}
+
+// isHeaderLine reports whether s looks like a "Name: value" header, where Name is made of
+// letters, digits and hyphens and starts with a letter
+func isHeaderLine(s string) bool {
+	name, _, found := strings.Cut(s, ": ")
+	if !found || name == "" {
+		return false
+	}
+	for i, r := range name {
+		isLetter := 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z'
+		if !isLetter && (i == 0 || r != '-' && !('0' <= r && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package wrapper
+
+import (
+	"strings"
+	"unicode"
+)
+
+// homoglyphs lists, for each character of the marker text, the non-ASCII runes that render
+// like it: Cyrillic and Greek letters and angle-bracket lookalikes. Fullwidth forms are
+// mapped arithmetically by foldHomoglyphs rather than listed.
+var homoglyphs = map[rune]string{
+	'A': "\u0410\u0430\u0391",             // Cyrillic A a, Greek Alpha
+	'C': "\u0421\u0441\u03f9",             // Cyrillic Es es, Greek lunate Sigma
+	'E': "\u0415\u0435\u0395",             // Cyrillic Ie ie, Greek Epsilon
+	'N': "\u039d",                         // Greek Nu
+	'O': "\u041e\u043e\u039f\u03bf",       // Cyrillic O o, Greek Omicron omicron
+	'S': "\u0405\u0455",                   // Cyrillic Dze dze
+	'T': "\u0422\u03a4",                   // Cyrillic Te, Greek Tau
+	'X': "\u0425\u0445\u03a7",             // Cyrillic Ha ha, Greek Chi
+	'<': "\u2039\u3008\u27e8\ufe64\u02c2", // single guillemet, CJK and math angle brackets, small and modifier less-than
+	'>': "\u203a\u3009\u27e9\ufe65\u02c3", // their closing counterparts
+}
+
+// homoglyphASCII maps each rune in homoglyphs to the ASCII character it imitates
+var homoglyphASCII = func() map[rune]rune {
+	m := map[rune]rune{}
+	for ascii, lookalikes := range homoglyphs {
+		for _, r := range lookalikes {
+			m[r] = ascii
+		}
+	}
+	return m
+}()
+
+// DetectHomoglyphMarkers reports whether content contains text that reads as the start or
+// end marker once lookalike characters are taken at face value, e.g. the end marker spelled
+// with Cyrillic or Greek capitals or fullwidth forms. Invisible format characters such
+// as zero-width spaces and bidi controls are ignored, as a reader would, and letter case
+// doesn't matter. The plain ASCII markers alone don't count; Validate reports those.
+func DetectHomoglyphMarkers(content string) bool {
+	folded := foldHomoglyphs(content)
+	plain := strings.Map(func(r rune) rune {
+		if r <= unicode.MaxASCII {
+			return rune(upperASCII(byte(r)))
+		}
+		return r
+	}, content)
+
+	for _, name := range markerNames {
+		marker := "<<<" + name
+		if strings.Count(folded, marker) > strings.Count(plain, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// foldHomoglyphs maps lookalike and fullwidth runes to ASCII, upper-cases ASCII letters and
+// drops invisible format characters and variation selectors. Other runes are kept.
+func foldHomoglyphs(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r <= unicode.MaxASCII:
+			return rune(upperASCII(byte(r)))
+		case '\uff01' <= r && r <= '\uff5e':
+			// Fullwidth ASCII block
+			return rune(upperASCII(byte(r - 0xfee0)))
+		case unicode.Is(unicode.Cf, r), unicode.Is(unicode.Variation_Selector, r):
+			return -1
+		}
+		if ascii, ok := homoglyphASCII[r]; ok {
+			return ascii
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,39 @@
+package wrapper
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultMaxResponseBytes caps how much of a response body WrapResponse reads
+const DefaultMaxResponseBytes = 10 << 20
+
+// WrapResponse reads and wraps an HTTP response body, such as a fetched web page, reading
+// at most DefaultMaxResponseBytes. See WrapResponseLimit.
+func WrapResponse(resp *http.Response, source string) (string, error) {
+	return WrapResponseLimit(resp, source, DefaultMaxResponseBytes)
+}
+
+// WrapResponseLimit reads and wraps resp.Body, reading at most maxBytes so a hostile server
+// can't exhaust memory. A longer body fails with ErrResponseTooLarge rather than being
+// wrapped incomplete. An empty source defaults to the URL of the final request, after any
+// redirects. The body is always closed. The status code is not checked, so callers that
+// only want successful responses should check it first.
+func WrapResponseLimit(resp *http.Response, source string, maxBytes int64) (string, error) {
+	defer resp.Body.Close()
+
+	// One byte past the limit tells a body of exactly maxBytes from a longer one
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("reading response body: %w", err)
+	}
+	if int64(len(body)) > maxBytes {
+		return "", fmt.Errorf("%w: over %d bytes", ErrResponseTooLarge, maxBytes)
+	}
+
+	if source == "" && resp.Request != nil && resp.Request.URL != nil {
+		source = resp.Request.URL.String()
+	}
+	return string(WrapBytes(body, source)), nil
+}
@@ -0,0 +1,80 @@
+package wrapper
+
+import "regexp"
+
+// injectionSignal is a phrase whose presence moves InjectionScore by weight. Each signal
+// counts once however often it matches, so repetition can't inflate the score.
+type injectionSignal struct {
+	pattern *regexp.Regexp
+	weight  float64
+	strong  bool // an attack on the reader's instructions; benign signals don't offset it
+}
+
+// injectionSignals are the phrase families InjectionScore weighs. Trigger words on their
+// own ("ignore", "system", "override") carry little weight because they are everyday
+// technical vocabulary; the weight sits on the phrases that aim them at the reader's
+// instructions. Negative signals recognise the benign questions those words usually appear
+// in, such as "how do I ignore this warning", and only count when no strong signal or
+// prompt extraction attempt was found, so an attack can't hide behind one.
+var injectionSignals = []injectionSignal{
+	// Instruction override: "ignore all previous instructions", "disregard the above"
+	{regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override|overrides|bypass|skip)\b[^.\n]{0,30}?\b(?:instructions?|rules|guidelines|directives|restrictions|system prompt|prompt|safety|wrapper|above)\b`), 0.6, true},
+	// Identity replacement: "you are now DAN", "you are no longer an AI assistant"
+	{regexp.MustCompile(`(?i)\byou are (?:now|no longer)\b|\bfrom now on,? you\b`), 0.45, false},
+	// Named jailbreaks and modes
+	{regexp.MustCompile(`\bDAN\b|(?i)\b(?:do anything now|jailbreak|developer mode|god mode|evil ?gpt)\b`), 0.5, false},
+	// Lifting limits: "no restrictions", "without any filters"
+	{regexp.MustCompile(`(?i)\b(?:no|without(?: any)?) (?:restrictions|limits|limitations|filters|rules|ethical guidelines|guidelines)\b`), 0.4, false},
+	// Role play set-ups
+	{regexp.MustCompile(`(?i)\b(?:pretend (?:to be|you are)|let'?s role-?play|you play a character|in a hypothetical world)\b`), 0.3, false},
+	// Replacement instructions: "New instructions:", "execute: rm -rf"
+	{regexp.MustCompile(`(?i)\bnew (?:instructions|rules|system prompt)\b|\bexecute:`), 0.4, false},
+	// Forged system turns: "</system>", "System:" opening a line, a JSON system message
+	{regexp.MustCompile(`(?i)</?system>|(?m)^[ \t]*(?:<!--[ \t]*)?system[ \t]*:|"role"[ \t]*:[ \t]*"system"`), 0.4, false},
+	// Instructions addressed to the model: "Note to AI:", "Act accordingly"
+	{regexp.MustCompile(`(?i)\bnote to (?:the )?(?:ai|assistant|model|llm)\b|\bact accordingly\b|\badmin mode\b`), 0.35, false},
+	// Bare trigger words, each weak on its own
+	{regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override|bypass)\b`), 0.1, false},
+	{regexp.MustCompile(`(?i)\b(?:system|prompt|instructions?|admin)\b`), 0.05, false},
+
+	// Benign technical questions: "How do I ignore ...", "Is there a way to bypass ..."
+	{regexp.MustCompile(`(?i)^\s*(?:how (?:do|can|should) (?:i|we|you)|how to|can i|should i|is there a way)\b`), -0.2, false},
+	// Trigger words aimed at ordinary code objects: "ignore this warning", "override this method"
+	{regexp.MustCompile(`(?i)\b(?:ignore|disregard|bypass|override|forget)\b (?:a |an |the |this |that |these |my |to )?(?:\w+ )?(?:warnings?|errors?|files?|changes|cache|methods?|functions?)\b`), -0.3, false},
+}
+
+// InjectionScore rates how strongly content reads like a prompt injection attempt, from 0
+// (no sign of one) to 1. It sums the weights of the phrase families found, clamped to that
+// range, so it is a cheap heuristic for triage and logging rather than a classifier; the
+// wrapper's protection never depends on it. Matching is case-insensitive except for the
+// "DAN" persona, which would otherwise match the name Dan.
+func InjectionScore(content string) float64 {
+	score, benign := 0.0, 0.0
+	strong := false
+	for _, s := range injectionSignals {
+		if !s.pattern.MatchString(content) {
+			continue
+		}
+		if s.weight < 0 {
+			benign += s.weight
+			continue
+		}
+		score += s.weight
+		strong = strong || s.strong
+	}
+	if len(detectPromptExtraction(content)) > 0 {
+		score += 0.6
+		strong = true
+	}
+	if !strong {
+		score += benign
+	}
+	// Not min/max: benchmark_test.go declares an int min in this package
+	switch {
+	case score < 0:
+		return 0
+	case score > 1:
+		return 1
+	}
+	return score
+}
@@ -0,0 +1,50 @@
+package wrapper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// InsertBlock replaces the single occurrence of placeholder in template with wrapped. The
+// block must pass UnwrapStrict (one trailing newline, as the CLI prints, is allowed), and
+// the placeholder must not sit inside a block already in the template, so several blocks can
+// be inserted one after another. Newlines are added where needed so both markers stay on
+// lines of their own.
+func InsertBlock(template, placeholder, wrapped string) (string, error) {
+	if placeholder == "" {
+		return "", errors.New("empty placeholder")
+	}
+	switch strings.Count(template, placeholder) {
+	case 0:
+		return "", fmt.Errorf("%w: %q", ErrPlaceholderNotFound, placeholder)
+	case 1:
+	default:
+		return "", fmt.Errorf("%w: %q", ErrAmbiguousPlaceholder, placeholder)
+	}
+
+	wrapped = strings.TrimSuffix(wrapped, "\n")
+	if _, err := UnwrapStrict(wrapped); err != nil {
+		return "", fmt.Errorf("inserting block: %w", err)
+	}
+
+	for _, seg := range SegmentTranscript(template) {
+		if !seg.Trusted && strings.Contains(seg.Content, placeholder) {
+			return "", fmt.Errorf("placeholder %q is inside a wrapped block", placeholder)
+		}
+	}
+
+	before, after, _ := strings.Cut(template, placeholder)
+	var sb strings.Builder
+	sb.Grow(len(template) + len(wrapped) + 2)
+	sb.WriteString(before)
+	if before != "" && !strings.HasSuffix(before, "\n") {
+		sb.WriteByte('\n')
+	}
+	sb.WriteString(wrapped)
+	if after != "" && !strings.HasPrefix(after, "\n") {
+		sb.WriteByte('\n')
+	}
+	sb.WriteString(after)
+	return sb.String(), nil
+}
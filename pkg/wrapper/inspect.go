@@ -0,0 +1,153 @@
+package wrapper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Inspection describes the structure found in a wrapped block. Offsets are byte offsets into
+// the inspected text and are -1 when the part was not found, or for SourceOffset, omitted.
+type Inspection struct {
+	Valid           bool     `json:"valid"`
+	StartMarker     string   `json:"start_marker"`
+	StartOffset     int      `json:"start_offset"`
+	Source          string   `json:"source"`
+	SourceOffset    int      `json:"source_offset"`
+	Headers         []string `json:"headers"` // header lines after the source line, e.g. Block-ID
+	SeparatorOffset int      `json:"separator_offset"`
+	ContentStart    int      `json:"content_start"`
+	ContentEnd      int      `json:"content_end"`
+	EndMarker       string   `json:"end_marker"`
+	EndOffset       int      `json:"end_offset"`
+	Warnings        []string `json:"warnings"`
+}
+
+// line is a single line of text and the offset it starts at
+type line struct {
+	text   string
+	offset int
+}
+
+// Inspect reports the structure of a single wrapped block without trusting its content.
+// Nonce-suffixed markers are recognised. The real end marker is taken to be the last
+// matching end marker line; earlier ones are reported as embedded, since that is where a
+// first-match parser would be misled. The header is read as ParseBlock reads it, and when
+// it doesn't parse the whole interior is reported as content, as SegmentTranscript does.
+func Inspect(wrapped string) Inspection {
+	in := Inspection{
+		StartOffset:     -1,
+		SourceOffset:    -1,
+		SeparatorOffset: -1,
+		ContentStart:    -1,
+		ContentEnd:      -1,
+		EndOffset:       -1,
+		Headers:         []string{},
+		Warnings:        []string{},
+	}
+	warn := func(format string, args ...any) {
+		in.Warnings = append(in.Warnings, fmt.Sprintf(format, args...))
+	}
+
+	lines := splitLines(wrapped)
+
+	first := -1
+	for i, l := range lines {
+		if isStartMarker(l.text) {
+			first = i
+			break
+		}
+	}
+	if first == -1 {
+		warn("no start marker line found")
+		return in
+	}
+	in.StartMarker = lines[first].text
+	in.StartOffset = lines[first].offset
+	if strings.TrimSpace(wrapped[:in.StartOffset]) != "" {
+		warn("text before start marker")
+	}
+	wantEnd := endMarkerFor(in.StartMarker)
+
+	last := -1
+	for j := len(lines) - 1; j > first; j-- {
+		if lines[j].text == wantEnd {
+			last = j
+			break
+		}
+	}
+	interiorStart := min(in.StartOffset+len(in.StartMarker)+1, len(wrapped))
+	interiorEnd := len(wrapped)
+	if last == -1 {
+		warn("no end marker line found")
+	} else {
+		in.EndMarker = wantEnd
+		in.EndOffset = lines[last].offset
+		// The newline before the end marker belongs to the wrapper, not the content
+		interiorEnd = max(interiorStart, in.EndOffset-1)
+		if strings.TrimSpace(wrapped[in.EndOffset+len(wantEnd):]) != "" {
+			warn("text after end marker")
+		}
+	}
+
+	interior := wrapped[interiorStart:interiorEnd]
+	var b Block
+	err := parseInterior(&b, interior, interiorStart)
+	in.ContentStart, in.ContentEnd = interiorStart, interiorEnd
+	if err != nil {
+		warn("%s at offset %d", err.Reason, err.Offset)
+	} else {
+		if !b.NoSource {
+			in.Source = b.Source
+			in.SourceOffset = interiorStart
+		}
+		for _, h := range b.Headers {
+			in.Headers = append(in.Headers, h.Name+": "+h.Value)
+		}
+		in.ContentStart = interiorEnd - len(b.Content)
+		in.SeparatorOffset = in.ContentStart - len(Separator) - 1
+	}
+
+	for _, l := range splitLines(wrapped[in.ContentStart:in.ContentEnd]) {
+		offset := in.ContentStart + l.offset
+		switch {
+		case l.text == wantEnd:
+			warn("embedded end marker line at offset %d", offset)
+		case isStartMarker(l.text):
+			warn("embedded start marker line at offset %d", offset)
+		case strings.Contains(l.text, EndMarker) || strings.Contains(l.text, StartMarker):
+			warn("inline marker text at offset %d", offset)
+		case strings.HasPrefix(l.text, SourcePrefix):
+			warn("forged source header at offset %d", offset)
+		}
+	}
+
+	in.Valid = err == nil && in.EndOffset >= 0
+	return in
+}
+
+// endMarkerFor returns the end marker that closes the start marker line start, nonce included
+func endMarkerFor(start string) string {
+	return "<<<END_" + strings.TrimPrefix(start, "<<<")
+}
+
+// isStartMarker reports whether text is the start marker, with or without a nonce suffix
+func isStartMarker(text string) bool {
+	if text == StartMarker {
+		return true
+	}
+	return strings.HasPrefix(text, strings.TrimSuffix(StartMarker, ">>>")+":") && strings.HasSuffix(text, ">>>")
+}
+
+// splitLines splits s on newlines, keeping the offset of each line
+func splitLines(s string) []line {
+	var lines []line
+	offset := 0
+	for {
+		i := strings.IndexByte(s[offset:], '\n')
+		if i == -1 {
+			return append(lines, line{s[offset:], offset})
+		}
+		lines = append(lines, line{s[offset : offset+i], offset})
+		offset += i + 1
+	}
+}
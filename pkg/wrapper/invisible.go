@@ -0,0 +1,33 @@
+package wrapper
+
+import "strings"
+
+// isInvisible reports whether r is one of the characters StripInvisible removes
+func isInvisible(r rune) bool {
+	switch {
+	case r == '\u200b', r == '\u200c', r == '\u200d', r == '\u2060', r == '\ufeff', r == '\u00ad':
+		return true
+	case '\U000e0000' <= r && r <= '\U000e007f':
+		// Tag characters
+		return true
+	case isBidiControl(r):
+		return true
+	}
+	return false
+}
+
+// StripInvisible removes characters that render as nothing but can hide or reorder text:
+// zero-width space, non-joiner and joiner, word joiner, byte order mark, soft hyphen, the
+// tag characters U+E0000 to U+E007F, and bidi embedding, override and isolate controls. It
+// returns the cleaned content and how many runes were removed. Wrapping never strips
+// anything on its own; this is also available as the strip-invisible transform.
+func StripInvisible(content string) (cleaned string, removed int) {
+	cleaned = strings.Map(func(r rune) rune {
+		if isInvisible(r) {
+			removed++
+			return -1
+		}
+		return r
+	}, content)
+	return cleaned, removed
+}
@@ -0,0 +1,42 @@
+package wrapper
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+// JSONType is the "type" field of every WrapJSON object, standing in for the markers
+const JSONType = "external_untrusted"
+
+// jsonBlock is the object WrapJSON emits
+type jsonBlock struct {
+	Type            string `json:"type"`
+	Source          string `json:"source"`
+	Content         string `json:"content"`
+	ContentEncoding string `json:"content_encoding,omitempty"`
+}
+
+// WrapJSON returns content as a single-line JSON object for prompt templates built from
+// JSON: {"type":"external_untrusted","source":...,"content":...}. The type field marks the
+// content as untrusted the way the markers do in a text block, and JSON string escaping
+// keeps the content from breaking out of its field. Content that is not valid UTF-8 can't
+// be carried losslessly in a JSON string, so it is base64-encoded instead and the object
+// gains "content_encoding":"base64". Invalid bytes in the source become U+FFFD.
+func WrapJSON(content, source string) (string, error) {
+	obj := jsonBlock{Type: JSONType, Source: source, Content: content}
+	if !utf8.ValidString(content) {
+		obj.Content = base64.StdEncoding.EncodeToString([]byte(content))
+		obj.ContentEncoding = "base64"
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(obj); err != nil {
+		return "", fmt.Errorf("encoding JSON block: %w", err)
+	}
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
+}
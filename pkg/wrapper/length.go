@@ -0,0 +1,22 @@
+package wrapper
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// lengthHeader names the size header added by WrapContentMeta
+const lengthHeader = "Length"
+
+// lengthValue returns the Length header value for content: "<bytes> bytes, <runes> runes".
+// Runes are counted as utf8.RuneCountInString does, so each byte of an invalid UTF-8
+// sequence counts as one rune.
+func lengthValue(content string) string {
+	return fmt.Sprintf("%d bytes, %d runes", len(content), utf8.RuneCountInString(content))
+}
+
+// WrapContentMeta wraps content with a "Length: <bytes> bytes, <runes> runes" header after
+// the source line, so a reader can tell whether the content arrived whole
+func WrapContentMeta(content, source string) string {
+	return Wrap(content, source, WithLength())
+}
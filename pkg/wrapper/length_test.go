@@ -0,0 +1,48 @@
+package wrapper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrapContentMeta(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"empty", "", "Length: 0 bytes, 0 runes"},
+		{"ascii", "hello world", "Length: 11 bytes, 11 runes"},
+		{"cjk", "日本語", "Length: 9 bytes, 3 runes"},
+		{"emoji", "hi \U0001F44B\U0001F3FD", "Length: 11 bytes, 5 runes"},
+		{"invalid utf-8", "ok\xff\xfe", "Length: 4 bytes, 4 runes"},
+		{"truncated rune", "日\xe6\x9c", "Length: 5 bytes, 3 runes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := WrapContentMeta(tt.content, "web")
+			lines := strings.Split(wrapped, "\n")
+			if lines[1] != "Source: web" || lines[2] != tt.want || lines[3] != "---" {
+				t.Errorf("header = %q, want Length line %q between source and separator", lines[:4], tt.want)
+			}
+
+			b, err := ParseBlock(wrapped)
+			if err != nil {
+				t.Fatalf("ParseBlock() error = %v", err)
+			}
+			if b.Content != tt.content || b.Source != "web" {
+				t.Errorf("ParseBlock() = %q, %q", b.Content, b.Source)
+			}
+			if len(b.Headers) != 1 || b.Headers[0].Name+": "+b.Headers[0].Value != tt.want {
+				t.Errorf("Headers = %+v, want %q", b.Headers, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapContentMeta_DefaultUnchanged(t *testing.T) {
+	if strings.Contains(WrapContent("data", "web"), "Length:") {
+		t.Error("WrapContent must not add a Length line")
+	}
+}
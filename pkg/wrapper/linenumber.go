@@ -0,0 +1,36 @@
+package wrapper
+
+import (
+	"strconv"
+	"strings"
+)
+
+// numberLines prefixes each line of content with its 1-based number and "| ", numbers
+// right-aligned to the widest. Prefixes go at line starts, so CRLF endings are kept as
+// they are. A final newline ends the last line rather than starting a new one, and empty
+// content has no lines to number.
+func numberLines(content string) string {
+	if content == "" {
+		return ""
+	}
+	body, trailing := strings.CutSuffix(content, "\n")
+	lines := strings.Split(body, "\n")
+	width := len(strconv.Itoa(len(lines)))
+
+	var b strings.Builder
+	b.Grow(len(content) + len(lines)*(width+2))
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		n := strconv.Itoa(i + 1)
+		b.WriteString(strings.Repeat(" ", width-len(n)))
+		b.WriteString(n)
+		b.WriteString("| ")
+		b.WriteString(line)
+	}
+	if trailing {
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
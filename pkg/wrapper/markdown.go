@@ -0,0 +1,49 @@
+package wrapper
+
+import "strings"
+
+// MarkdownInfo is the info string on every WrapMarkdown fence
+const MarkdownInfo = "untrusted"
+
+// WrapMarkdown returns content as a fenced Markdown code block for chat UIs that render
+// Markdown, with a "Source: ..." line before the fence:
+//
+//	Source: Web Search
+//	```untrusted
+//	content
+//	```
+//
+// The fence is one backtick longer than the longest run of backticks in the content, and
+// never shorter than three, so no line of the content can close it early. Line breaks in
+// the source become spaces to keep it on its line. A newline is added before the closing
+// fence unless the content already ends with one.
+func WrapMarkdown(content, source string) string {
+	fence := strings.Repeat("`", max(3, longestRun(content, '`')+1))
+	source = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(source)
+
+	var b strings.Builder
+	b.WriteString(SourcePrefix + source + "\n")
+	b.WriteString(fence + MarkdownInfo + "\n")
+	b.WriteString(content)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		b.WriteByte('\n')
+	}
+	b.WriteString(fence)
+	return b.String()
+}
+
+// longestRun returns the length of the longest run of c in s
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != c {
+			run = 0
+			continue
+		}
+		run++
+		if run > longest {
+			longest = run
+		}
+	}
+	return longest
+}
@@ -0,0 +1,91 @@
+package wrapper
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minMarkerRun is the bracket run length that can open or close a marker
+const minMarkerRun = 3
+
+// leftBrackets and rightBrackets are < and > plus lookalikes a model may read the same way
+const (
+	leftBrackets  = "<＜﹤‹〈⟨❮"
+	rightBrackets = ">＞﹥›〉⟩❯"
+)
+
+// markerRun is a marker-capable bracket run: content[start:end] holding n brackets
+type markerRun struct {
+	start, end, n int
+	left          bool
+}
+
+// markerRuns finds runs of at least minMarkerRun same-direction brackets in s, allowing
+// invisible format characters between them
+func markerRuns(s string) []markerRun {
+	var runs []markerRun
+	for i := 0; i < len(s); {
+		// Plain ASCII is by far the common case and can't start a run unless it is < or >
+		if c := s[i]; c < utf8.RuneSelf && c != '<' && c != '>' {
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		var brackets string
+		switch {
+		case strings.ContainsRune(leftBrackets, r):
+			brackets = leftBrackets
+		case strings.ContainsRune(rightBrackets, r):
+			brackets = rightBrackets
+		default:
+			i += size
+			continue
+		}
+
+		end, n := i, 0
+		for end < len(s) {
+			r, size := utf8.DecodeRuneInString(s[end:])
+			if strings.ContainsRune(brackets, r) {
+				n++
+			} else if !unicode.Is(unicode.Cf, r) {
+				break
+			}
+			end += size
+		}
+		if n >= minMarkerRun {
+			runs = append(runs, markerRun{start: i, end: end, n: n, left: brackets == leftBrackets})
+		}
+		i = end
+	}
+	return runs
+}
+
+// NeutralizeAllMarkers rewrites every run of three or more angle brackets in content as
+// HTML entities (<<< becomes &lt;&lt;&lt;) and returns the result with the number of runs
+// rewritten. Every marker, real or forged, needs such a run, so afterwards the content
+// cannot close or open a block however the marker text is disguised: split across lines,
+// interleaved with zero-width characters, or spelled with lookalike brackets. Invisible
+// format characters inside a run are dropped with it. Runs of one or two brackets, as in
+// "a << b" or "<br>", are left alone.
+func NeutralizeAllMarkers(content string) (string, int) {
+	runs := markerRuns(content)
+	if len(runs) == 0 {
+		return content, 0
+	}
+
+	var b strings.Builder
+	b.Grow(len(content))
+	last := 0
+	for _, run := range runs {
+		entity := "&gt;"
+		if run.left {
+			entity = "&lt;"
+		}
+		b.WriteString(content[last:run.start])
+		b.WriteString(strings.Repeat(entity, run.n))
+		last = run.end
+	}
+	b.WriteString(content[last:])
+	return b.String(), len(runs)
+}
@@ -0,0 +1,39 @@
+package wrapper
+
+import (
+	"fmt"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizationForm selects the Unicode normalization applied by WithNormalization
+type NormalizationForm int
+
+const (
+	// NFC composes characters canonically: "e" followed by U+0301, a combining acute
+	// accent, becomes "é". Text looks the same before and after.
+	NFC NormalizationForm = iota + 1
+
+	// NFKC also folds compatibility characters to their plain forms: the U+FB01 "fi" ligature
+	// becomes "fi" and fullwidth letters become ASCII, which exposes fullwidth marker
+	// lookalikes to EscapeMarkers. It changes how some text looks, so use it deliberately.
+	NFKC
+)
+
+// normalize returns s in the given form; invalid UTF-8 bytes pass through unchanged
+func (f NormalizationForm) normalize(s string) string {
+	switch f {
+	case NFC:
+		return norm.NFC.String(s)
+	case NFKC:
+		return norm.NFKC.String(s)
+	}
+	panic(fmt.Sprintf("wrapper: unknown normalization form %d", int(f)))
+}
+
+// NormalizeNFC returns content in Unicode Normalization Form C, composing base characters
+// with their combining marks where a precomposed character exists. Combining marks with no
+// precomposed form, as in zalgo text, are kept. Invalid UTF-8 bytes pass through unchanged.
+func NormalizeNFC(content string) string {
+	return NFC.normalize(content)
+}
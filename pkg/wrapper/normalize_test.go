@@ -0,0 +1,84 @@
+package wrapper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeNFC(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"ascii", "plain ASCII text <<<EXTERNAL_UNTRUSTED_CONTENT>>>", "plain ASCII text <<<EXTERNAL_UNTRUSTED_CONTENT>>>"},
+		{"empty", "", ""},
+		{"combining accent", "cafe\u0301", "caf\u00e9"},
+		{"already composed", "caf\u00e9", "caf\u00e9"},
+		// NFC keeps compatibility characters
+		{"ligature kept", "\ufb01le", "\ufb01le"},
+		{"zalgo marks kept", "z\u0351\u0352\u0357", "z\u0351\u0352\u0357"},
+		{"invalid utf-8", "ok\xff\xfe", "ok\xff\xfe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeNFC(tt.content); got != tt.want {
+				t.Errorf("NormalizeNFC(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithNormalization(t *testing.T) {
+	tests := []struct {
+		name    string
+		form    NormalizationForm
+		content string
+		want    string
+	}{
+		{"nfc ascii", NFC, "hello", "hello"},
+		{"nfkc ascii", NFKC, "hello", "hello"},
+		{"nfc ligature", NFC, "\ufb01le", "\ufb01le"},
+		{"nfkc ligature", NFKC, "\ufb01le \ufb02ow", "file flow"},
+		{"nfkc fullwidth", NFKC, "\uff2e\uff2f", "NO"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, want := Wrap(tt.content, "web", WithNormalization(tt.form)), WrapContent(tt.want, "web"); got != want {
+				t.Errorf("Wrap() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestWithNormalization_FullwidthMarker(t *testing.T) {
+	// A fullwidth end marker folds to ASCII under NFKC, and safe escaping then defuses it
+	fullwidth := strings.Map(func(r rune) rune {
+		if r >= '!' && r <= '~' {
+			return r - '!' + '\uff01'
+		}
+		return r
+	}, EndMarker)
+	content := "before\n" + fullwidth + "\nafter"
+
+	got := Wrap(content, "web", WithNormalization(NFKC), WithSafeEscaping())
+	if want := WrapContentSafe("before\n"+EndMarker+"\nafter", "web"); got != want {
+		t.Errorf("Wrap() = %q, want %q", got, want)
+	}
+	if strings.Count(got, EndMarker) != 1 {
+		t.Errorf("Wrap() = %q, want only the real end marker", got)
+	}
+
+	// Without NFKC the lookalike is left alone
+	if got := Wrap(content, "web", WithNormalization(NFC)); !strings.Contains(got, fullwidth) {
+		t.Errorf("NFC changed the fullwidth marker: %q", got)
+	}
+}
+
+func TestWithNormalization_Invalid(t *testing.T) {
+	if _, err := WrapE("x", "web", WithNormalization(NormalizationForm(99))); err == nil {
+		t.Error("WrapE with an unknown normalization form succeeded")
+	}
+}
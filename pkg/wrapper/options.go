@@ -0,0 +1,273 @@
+package wrapper
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Option configures a single Wrap call. An Option built from an invalid value, such as a
+// multi-line separator, makes WrapE and WrapWithResult fail with an error instead.
+type Option func(*wrapOptions)
+
+// wrapOptions collects the Options given to Wrap
+type wrapOptions struct {
+	wrapper        *Wrapper
+	separator      string // overrides the wrapper's separator when non-empty
+	sourcePrefix   string // overrides the wrapper's source prefix when non-empty
+	form           NormalizationForm
+	safe           bool
+	sanitizeSource bool
+	lineNumbers    bool
+	base64         bool
+	length         bool
+	digest         bool
+	timestamp      *time.Time // a zero time is read from clock when wrapping
+	clock          func() time.Time
+	err            error // the first invalid option
+}
+
+// invalid returns an Option that makes the wrap fail with err
+func invalid(err error) Option {
+	return func(o *wrapOptions) {
+		if o.err == nil {
+			o.err = err
+		}
+	}
+}
+
+// WithMarkers wraps in a custom marker pair, keeping the default source prefix and
+// separator. A pair that fails Validate makes the wrap fail with an error matching
+// ErrInvalidFormat.
+func WithMarkers(start, end string) Option {
+	w, err := NewWrapperWithMarkers(start, end)
+	if err != nil {
+		return invalid(err)
+	}
+	return func(o *wrapOptions) { o.wrapper = w }
+}
+
+// WithSeparator replaces the "---" line between the header and the content, for templates
+// that expect, say, "===". It applies to custom markers from WithMarkers too. An empty or
+// multi-line separator makes the wrap fail with an error matching ErrInvalidFormat.
+func WithSeparator(separator string) Option {
+	if err := checkFormatLine("separator", separator); err != nil {
+		return invalid(err)
+	}
+	return func(o *wrapOptions) { o.separator = separator }
+}
+
+// WithSourcePrefix replaces the "Source: " prefix of the source line, as with a localized
+// "Quelle: ". Parsers such as ParseBlock only read the default prefix. An empty or
+// multi-line prefix makes the wrap fail with an error matching ErrInvalidFormat.
+func WithSourcePrefix(prefix string) Option {
+	if err := checkFormatLine("source prefix", prefix); err != nil {
+		return invalid(err)
+	}
+	return func(o *wrapOptions) { o.sourcePrefix = prefix }
+}
+
+// checkFormatLine reports an error matching ErrInvalidFormat unless value is a non-empty
+// single line
+func checkFormatLine(name, value string) error {
+	if value == "" {
+		return fmt.Errorf("%w: empty %s", ErrInvalidFormat, name)
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("%w: %s contains a line break", ErrInvalidFormat, name)
+	}
+	return nil
+}
+
+// WithNormalization normalizes the content to form before any escaping, so that with NFKC
+// fullwidth marker lookalikes fold to ASCII and WithSafeEscaping then escapes them. The
+// source is left as given. A form other than NFC or NFKC makes the wrap fail.
+func WithNormalization(form NormalizationForm) Option {
+	if form != NFC && form != NFKC {
+		return invalid(fmt.Errorf("unknown normalization form %d", int(form)))
+	}
+	return func(o *wrapOptions) { o.form = form }
+}
+
+// WithSafeEscaping passes content and source through EscapeMarkers, as WrapContentSafe does.
+// With custom markers from WithMarkers, each occurrence of either marker also gets a
+// backslash after its first character, so "[[END]]" becomes "[\[END]]"; markers of a single
+// character or containing a backslash can't be escaped this way and make the wrap fail.
+func WithSafeEscaping() Option {
+	return func(o *wrapOptions) { o.safe = true }
+}
+
+// WithSanitizedSource passes the source through SanitizeSource, so it stays on one line and
+// holds no marker, custom markers from WithMarkers included. Combined with WithSafeEscaping
+// the source is escaped only once.
+func WithSanitizedSource() Option {
+	return func(o *wrapOptions) { o.sanitizeSource = true }
+}
+
+// WithLineNumbers prefixes each content line with its number and "| ", as in " 9| text"
+// and "10| text", for pointing at lines of a block in injection reports. Numbering follows
+// any escaping, so the prefixes never touch a marker.
+func WithLineNumbers() Option {
+	return func(o *wrapOptions) { o.lineNumbers = true }
+}
+
+// WithBase64 base64-encodes the content, after any normalization and escaping, and adds the
+// "Content-Encoding: base64" header of WrapBase64
+func WithBase64() Option {
+	return func(o *wrapOptions) { o.base64 = true }
+}
+
+// WithLength adds the "Length: <bytes> bytes, <runes> runes" header of WrapContentMeta
+func WithLength() Option {
+	return func(o *wrapOptions) { o.length = true }
+}
+
+// WithDigest adds the "SHA256: <hex>" header of WrapContentWithDigest
+func WithDigest() Option {
+	return func(o *wrapOptions) { o.digest = true }
+}
+
+// WithTimestamp adds the "Wrapped-At:" header of WrapContentAt with the time t. A zero t
+// stands for the time of wrapping, read from the clock set by WithClock.
+func WithTimestamp(t time.Time) Option {
+	return func(o *wrapOptions) { o.timestamp = &t }
+}
+
+// WithClock sets the clock a zero WithTimestamp time is read from, so tests can fix the time
+// without touching package state. The default, and what a nil clock restores, is time.Now.
+func WithClock(clock func() time.Time) Option {
+	if clock == nil {
+		clock = time.Now
+	}
+	return func(o *wrapOptions) { o.clock = clock }
+}
+
+// WrapResult is a wrapped block together with what wrapping did to the content, for callers
+// that would otherwise parse the block again to find out
+type WrapResult struct {
+	// Wrapped is the block, as Wrap returns it
+	Wrapped string
+	// ContentBytes is the length of the content as it appears in the block
+	ContentBytes int
+	// MarkersEscaped counts the marker names escaped in the content and source, which is
+	// zero unless WithSafeEscaping or WithSanitizedSource is given
+	MarkersEscaped int
+	// EncodingUsed is the block's Content-Encoding: Base64Encoding with WithBase64, and
+	// empty when the content is carried as is
+	EncodingUsed string
+}
+
+// Wrap wraps untrusted content like WrapContent, configured by opts. Content is normalized
+// first, then escaped, numbered and encoded. Headers appear after the source line in a fixed
+// order whatever the order of opts: Content-Encoding, Length, SHA256, then Wrapped-At.
+// Length and SHA256 describe the content as it appears in the block, after all of those
+// steps, so VerifyDigest accepts the result. With no options Wrap is WrapContent.
+//
+// Wrap panics if an option is invalid, which for options fixed in the code is a programming
+// error. Use WrapE when options come from configuration or user input.
+func Wrap(content, source string, opts ...Option) string {
+	if len(opts) == 0 {
+		return defaultWrapper.Wrap(content, source)
+	}
+	r, err := WrapWithResult(content, source, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return r.Wrapped
+}
+
+// WrapE is Wrap returning an invalid option's error, such as one matching ErrInvalidFormat
+// for a multi-line separator, instead of panicking
+func WrapE(content, source string, opts ...Option) (string, error) {
+	r, err := WrapWithResult(content, source, opts...)
+	return r.Wrapped, err
+}
+
+// WrapWithResult is WrapE returning a WrapResult that describes the block
+func WrapWithResult(content, source string, opts ...Option) (WrapResult, error) {
+	o := wrapOptions{wrapper: defaultWrapper, clock: time.Now}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.err != nil {
+		return WrapResult{}, o.err
+	}
+	if o.separator != "" || o.sourcePrefix != "" {
+		w := *o.wrapper
+		if o.separator != "" {
+			w.Separator = o.separator
+		}
+		if o.sourcePrefix != "" {
+			w.SourcePrefix = o.sourcePrefix
+		}
+		o.wrapper = &w
+	}
+	var r WrapResult
+	if o.form != 0 {
+		content = o.form.normalize(content)
+	}
+	if o.safe {
+		content, r.MarkersEscaped = escapeMarkers(content)
+	}
+	var n int
+	switch {
+	case o.sanitizeSource:
+		source, n = sanitizeSource(source)
+	case o.safe:
+		source, n = escapeMarkers(source)
+	}
+	r.MarkersEscaped += n
+	// EscapeMarkers only knows the default marker names, so custom markers are escaped too
+	if custom := o.wrapper.StartMarker != StartMarker || o.wrapper.EndMarker != EndMarker; custom && (o.safe || o.sanitizeSource) {
+		for _, marker := range []string{o.wrapper.StartMarker, o.wrapper.EndMarker} {
+			if !canEscapeLiteral(marker) {
+				return WrapResult{}, fmt.Errorf("%w: marker %q can't be escaped; safe escaping needs markers of two or more characters without backslashes", ErrInvalidFormat, marker)
+			}
+			if o.safe {
+				content, n = escapeLiteralMarker(content, marker)
+				r.MarkersEscaped += n
+			}
+			source, n = escapeLiteralMarker(source, marker)
+			r.MarkersEscaped += n
+		}
+	}
+	if o.lineNumbers {
+		content = numberLines(content)
+	}
+
+	var headers []string
+	if o.base64 {
+		content = base64.StdEncoding.EncodeToString([]byte(content))
+		r.EncodingUsed = Base64Encoding
+		headers = append(headers, contentEncodingHeader+": "+Base64Encoding)
+	}
+	r.ContentBytes = len(content)
+	if o.length {
+		headers = append(headers, lengthHeader+": "+lengthValue(content))
+	}
+	if o.digest {
+		headers = append(headers, digestHeader+": "+contentDigest(content))
+	}
+	if o.timestamp != nil {
+		t := *o.timestamp
+		if t.IsZero() {
+			t = o.clock()
+		}
+		headers = append(headers, wrappedAtHeader+": "+t.UTC().Format(time.RFC3339))
+	}
+	if len(headers) == 0 {
+		r.Wrapped = o.wrapper.Wrap(content, source)
+		return r, nil
+	}
+
+	w := o.wrapper
+	var sb strings.Builder
+	sb.WriteString(w.StartMarker + "\n" + w.SourcePrefix + source + "\n")
+	for _, h := range headers {
+		sb.WriteString(h + "\n")
+	}
+	sb.WriteString(w.Separator + "\n" + content + "\n" + w.EndMarker)
+	r.Wrapped = sb.String()
+	return r, nil
+}
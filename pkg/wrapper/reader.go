@@ -0,0 +1,20 @@
+package wrapper
+
+import (
+	"io"
+	"strings"
+)
+
+// NewReader returns a reader that yields the wrapped form of src as it is read: the header,
+// then src's bytes, then the end marker once src reports io.EOF. Nothing is buffered, so a
+// network stream can be wrapped on its way through a pipeline. Draining the reader gives
+// WrapContent of src's content; like ChunkWrapper it does not escape markers, so pass src
+// through NewWriter instead when the content needs that. An error from src other than
+// io.EOF is returned as is and the end marker is not emitted.
+func NewReader(src io.Reader, source string) io.Reader {
+	return io.MultiReader(
+		strings.NewReader(StartMarker+"\n"+SourcePrefix+source+"\n"+Separator+"\n"),
+		src,
+		strings.NewReader("\n"+EndMarker),
+	)
+}
@@ -0,0 +1,60 @@
+package wrapper
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestNewReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty", ""},
+		{"simple", "hello world"},
+		{"multiline", "line one\nline two\n"},
+		{"marker in content", "<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>"},
+		{"binary", "\x00\xff\xfe\x00"},
+		{"large", strings.Repeat("0123456789abcdef", 4096)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := io.ReadAll(NewReader(strings.NewReader(tt.content), "net"))
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if want := WrapContent(tt.content, "net"); string(got) != want {
+				t.Errorf("output = %q, want %q", got, want)
+			}
+
+			// A one-byte-at-a-time source must give the same bytes
+			got, err = io.ReadAll(NewReader(iotest.OneByteReader(strings.NewReader(tt.content)), "net"))
+			if err != nil {
+				t.Fatalf("ReadAll() one byte at a time error = %v", err)
+			}
+			if want := WrapContent(tt.content, "net"); string(got) != want {
+				t.Errorf("one byte at a time output = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestNewReader_SourceError(t *testing.T) {
+	wantErr := errors.New("connection reset")
+	src := io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(wantErr))
+
+	got, err := io.ReadAll(NewReader(src, "net"))
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("ReadAll() error = %v, want %v", err, wantErr)
+	}
+	if !strings.HasSuffix(string(got), "partial") {
+		t.Errorf("output before the error = %q, want the header and partial content", got)
+	}
+	if strings.Contains(string(got), "<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>") {
+		t.Errorf("End marker emitted after a read error: %q", got)
+	}
+}
@@ -0,0 +1,42 @@
+package wrapper
+
+import "regexp"
+
+// secretPatterns are the secret patterns Redact masks and DefaultAuditPatterns matches, in
+// the order they are applied. Private keys go first so the base64 inside a key block isn't
+// matched piecemeal by later patterns, and key=value secrets go last and skip a value that
+// is already a mask. None of the patterns match '<' or '>', so redaction never touches
+// marker text.
+var secretPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	// An unterminated block, such as a key cut off by truncation, is masked to the end
+	{"private-key", regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY-----(?s:.*?)(?:-----END (?:[A-Z0-9]+ )*PRIVATE KEY-----|\z)`)},
+	{"aws-access-key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"bearer-token", regexp.MustCompile(`(?i)\bbearer[ \t]+[A-Za-z0-9\-._~+/]+=*`)},
+	{"api-key", regexp.MustCompile(`\b(?:sk|pk|ghp|gho|ghs|xox[abpr])[-_][A-Za-z0-9\-_]{16,}`)},
+	{"email", regexp.MustCompile(`\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}\b`)},
+	{"secret", regexp.MustCompile(`(?i)\b(?:password|passwd|secret|api[_-]?key|token)[ \t]*[:=][ \t]*[^\s<>\[][^\s<>]*`)},
+}
+
+// Redact masks common secrets in content with "[REDACTED:<type>]": PEM private key blocks
+// (private-key), AWS access key IDs (aws-access-key), bearer tokens (bearer-token), prefixed
+// API keys such as sk-... and ghp_... (api-key), email addresses (email) and password=,
+// token: and similar assignments (secret). It returns the redacted content and how many
+// secrets were masked.
+//
+// Redaction is opt-in and meant to run on the content before it is wrapped, so the markers
+// added afterwards sit exactly where they would without it. The patterns are fixed and
+// applied in a fixed order, so the same input always gives the same output. This is also
+// available as the redact transform.
+func Redact(content string) (redacted string, count int) {
+	for _, r := range secretPatterns {
+		mask := "[REDACTED:" + r.kind + "]"
+		content = r.pattern.ReplaceAllStringFunc(content, func(string) string {
+			count++
+			return mask
+		})
+	}
+	return content, count
+}
@@ -0,0 +1,124 @@
+package wrapper
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// markerNames are the marker bodies EscapeMarkers looks for after "<<<". The end name is
+// first because the start name is its suffix.
+var markerNames = []string{"END_EXTERNAL_UNTRUSTED_CONTENT", "EXTERNAL_UNTRUSTED_CONTENT"}
+
+// WrapContentSafe wraps content like WrapContent after passing content and source through
+// EscapeMarkers, so the output holds exactly one real start marker and one real end marker,
+// even to a parser that splits on the first marker substring rather than the first marker
+// line. Recover the original text with UnescapeMarkers on the parsed content and source.
+func WrapContentSafe(content, source string) string {
+	return WrapContent(EscapeMarkers(content), EscapeMarkers(source))
+}
+
+// EscapeMarkers inserts a backslash after the "<<<" of every marker name in s, so
+// <<<END_EXTERNAL_UNTRUSTED_CONTENT>>> becomes <<<\END_EXTERNAL_UNTRUSTED_CONTENT>>>.
+// Matching ignores ASCII case, to defeat parsers that compare markers case-insensitively,
+// and covers nonce-suffixed and unterminated markers too. Names already preceded by
+// backslashes get one more, which keeps the escaping reversible with UnescapeMarkers.
+func EscapeMarkers(s string) string {
+	escaped, _ := escapeMarkers(s)
+	return escaped
+}
+
+// escapeMarkers is EscapeMarkers counting the marker names escaped
+func escapeMarkers(s string) (string, int) {
+	n := 0
+	escaped := rewriteMarkerNames(s, func(sb *strings.Builder, slashes int) {
+		n++
+		sb.WriteString(strings.Repeat(`\`, slashes+1))
+	})
+	return escaped, n
+}
+
+// escapeLiteralMarker inserts a backslash after the first character of every occurrence of
+// marker in s, overlapping ones included, and counts them. This is how Wrap escapes custom
+// markers, which EscapeMarkers doesn't know about; UnescapeMarkers doesn't reverse it.
+// Afterwards s can't contain marker as long as canEscapeLiteral(marker) holds.
+func escapeLiteralMarker(s, marker string) (string, int) {
+	_, first := utf8.DecodeRuneInString(marker)
+	var sb strings.Builder
+	n, last := 0, 0
+	for pos := 0; ; {
+		i := strings.Index(s[pos:], marker)
+		if i == -1 {
+			break
+		}
+		split := pos + i + first
+		sb.WriteString(s[last:split])
+		sb.WriteByte('\\')
+		last, pos = split, split
+		n++
+	}
+	if n == 0 {
+		return s, 0
+	}
+	sb.WriteString(s[last:])
+	return sb.String(), n
+}
+
+// canEscapeLiteral reports whether escapeLiteralMarker removes every occurrence of marker:
+// a backslash inserted after the first character splits an occurrence only if there is a
+// second character, and can't form a new occurrence only if marker has no backslash
+func canEscapeLiteral(marker string) bool {
+	return utf8.RuneCountInString(marker) >= 2 && !strings.Contains(marker, `\`)
+}
+
+// UnescapeMarkers reverses EscapeMarkers, removing one backslash between "<<<" and each
+// marker name. UnescapeMarkers(EscapeMarkers(s)) == s for every s.
+func UnescapeMarkers(s string) string {
+	return rewriteMarkerNames(s, func(sb *strings.Builder, slashes int) {
+		sb.WriteString(strings.Repeat(`\`, max(slashes-1, 0)))
+	})
+}
+
+// rewriteMarkerNames copies s, letting writeSlashes replace the run of backslashes between
+// each "<<<" and a following marker name
+func rewriteMarkerNames(s string, writeSlashes func(sb *strings.Builder, slashes int)) string {
+	var sb strings.Builder
+	last := 0
+	for pos := 0; ; {
+		i := strings.Index(s[pos:], "<<<")
+		if i == -1 {
+			break
+		}
+		i += pos
+		pos = i + 1
+
+		nameStart := i + 3
+		for nameStart < len(s) && s[nameStart] == '\\' {
+			nameStart++
+		}
+		if !hasMarkerName(s[nameStart:]) {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.Grow(len(s) + 16)
+		}
+		sb.WriteString(s[last : i+3])
+		writeSlashes(&sb, nameStart-(i+3))
+		last = nameStart
+		pos = nameStart
+	}
+	if last == 0 {
+		return s
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+// hasMarkerName reports whether s starts with a marker name, ignoring ASCII case
+func hasMarkerName(s string) bool {
+	for _, name := range markerNames {
+		if len(s) >= len(name) && strings.EqualFold(s[:len(name)], name) {
+			return true
+		}
+	}
+	return false
+}
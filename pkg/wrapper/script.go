@@ -0,0 +1,71 @@
+package wrapper
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// frequentScripts are checked before falling back to the full unicode.Scripts table
+var frequentScripts = []string{
+	"Latin", "Greek", "Cyrillic", "Armenian", "Cherokee",
+	"Han", "Hiragana", "Katakana", "Hangul", "Arabic", "Hebrew", "Devanagari", "Thai",
+}
+
+// confusableScripts have letters that pass for Latin ones; mixing two of them inside a
+// single word is the shape of a homoglyph attack such as a Cyrillic-spelled end marker
+var confusableScripts = []string{"Latin", "Greek", "Cyrillic", "Armenian", "Cherokee"}
+
+// Scripts returns the sorted names of the Unicode scripts used in content, ignoring the
+// Common and Inherited pseudo-scripts that punctuation, digits and combining marks belong to
+func Scripts(content string) []string {
+	seen := map[string]bool{}
+	for _, r := range content {
+		if name := scriptOf(r); name != "" {
+			seen[name] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// scriptOf returns the script r belongs to, or "" for Common, Inherited and unassigned runes
+func scriptOf(r rune) string {
+	if r < utf8.RuneSelf {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' {
+			return "Latin"
+		}
+		return ""
+	}
+	for _, name := range frequentScripts {
+		if unicode.Is(unicode.Scripts[name], r) {
+			return name
+		}
+	}
+	for name, table := range unicode.Scripts {
+		if name != "Common" && name != "Inherited" && unicode.Is(table, r) {
+			return name
+		}
+	}
+	return ""
+}
+
+// confusableScriptBit returns a bit identifying r's script among confusableScripts, or 0
+func confusableScriptBit(r rune) uint {
+	if r < utf8.RuneSelf {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' {
+			return 1
+		}
+		return 0
+	}
+	for i, name := range confusableScripts {
+		if unicode.Is(unicode.Scripts[name], r) {
+			return 1 << i
+		}
+	}
+	return 0
+}
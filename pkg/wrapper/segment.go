@@ -0,0 +1,59 @@
+package wrapper
+
+// Segment is a run of a transcript that is either trusted text or the content of a wrapped block
+type Segment struct {
+	Trusted bool
+	Source  string // source label of an untrusted segment; empty for trusted text
+	Content string
+}
+
+// SegmentTranscript splits a transcript into trusted text and the untrusted content of each
+// wrapped block, in order. Marker, source and separator lines belong to neither side.
+//
+// A block opens at a start marker line, nonce-suffixed or not, and closes at the next line
+// holding the matching end marker, which is also how a naive downstream parser reads it:
+// content after an injected end marker line comes back as trusted. A start marker that is
+// never closed makes the rest of the transcript untrusted, and a stray end marker is plain
+// text. The header is read as ParseBlock reads it; when it doesn't parse, the whole block
+// interior is reported as content.
+func SegmentTranscript(transcript string) []Segment {
+	var segments []Segment
+	addTrusted := func(text string) {
+		if text != "" {
+			segments = append(segments, Segment{Trusted: true, Content: text})
+		}
+	}
+
+	lines := splitLines(transcript)
+	pos := 0
+	for i := 0; i < len(lines); i++ {
+		start := lines[i]
+		if !isStartMarker(start.text) {
+			continue
+		}
+		addTrusted(transcript[pos:start.offset])
+
+		interiorStart := min(start.offset+len(start.text)+1, len(transcript))
+		interiorEnd := len(transcript)
+		pos = len(transcript)
+		wantEnd := endMarkerFor(start.text)
+		for i++; i < len(lines); i++ {
+			if end := lines[i]; end.text == wantEnd {
+				// The newline before the end marker belongs to the wrapper, not the content
+				interiorEnd = max(interiorStart, end.offset-1)
+				pos = min(end.offset+len(end.text)+1, len(transcript))
+				break
+			}
+		}
+
+		interior := transcript[interiorStart:interiorEnd]
+		var b Block
+		if err := parseInterior(&b, interior, interiorStart); err != nil {
+			b.Source, b.Content = "", interior
+		}
+		segments = append(segments, Segment{Source: b.Source, Content: b.Content})
+	}
+	addTrusted(transcript[pos:])
+
+	return segments
+}
@@ -0,0 +1,33 @@
+package wrapper
+
+import "strings"
+
+// WrapSegments wraps several pieces of content, such as retrieved chunks from different
+// sources, in one block. Each segment gets its own "Source: ..." and "---" sub-header
+// inside the single outer marker pair:
+//
+//	<<<EXTERNAL_UNTRUSTED_CONTENT>>>
+//	Source: first
+//	---
+//	first content
+//	Source: second
+//	---
+//	second content
+//	<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>
+//
+// Every content and source passes through EscapeMarkers, so no segment can close the outer
+// block early, and line breaks in a source become spaces. Sub-headers are for the reader's
+// benefit only: a segment can print lines that look like another sub-header, and all of
+// the block is untrusted whichever segment the text appears to belong to. The Trusted field
+// of each Segment is ignored. With no segments the block is empty.
+func WrapSegments(segments []Segment) string {
+	var sb strings.Builder
+	sb.WriteString(StartMarker + "\n")
+	for _, seg := range segments {
+		source := legendNewlines.Replace(EscapeMarkers(seg.Source))
+		sb.WriteString(SourcePrefix + source + "\n" + Separator + "\n")
+		sb.WriteString(EscapeMarkers(seg.Content) + "\n")
+	}
+	sb.WriteString(EndMarker)
+	return sb.String()
+}
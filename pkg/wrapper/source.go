@@ -0,0 +1,36 @@
+package wrapper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SanitizeSource makes a source label safe to put on the Source line. Line feeds and
+// carriage returns become the visible escapes \n and \r, other control characters except
+// tab become \xNN, and marker names are escaped as EscapeMarkers does. The result is one
+// line that can't add header lines or close the block, whatever a naive parser does with
+// it. Backslashes already in the label are left alone, so the escaping is for display and
+// can't be reversed exactly. WrapContent doesn't sanitize its source; use
+// WithSanitizedSource to have Wrap do it.
+func SanitizeSource(source string) string {
+	sanitized, _ := sanitizeSource(source)
+	return sanitized
+}
+
+// sanitizeSource is SanitizeSource counting the marker names escaped
+func sanitizeSource(source string) (string, int) {
+	var b strings.Builder
+	for i := 0; i < len(source); i++ {
+		switch c := source[i]; {
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c < 0x20 && c != '\t' || c == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return escapeMarkers(b.String())
+}
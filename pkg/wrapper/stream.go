@@ -0,0 +1,41 @@
+package wrapper
+
+import (
+	"context"
+	"io"
+)
+
+// streamChunkSize is how much WrapStream copies between checks of its context
+const streamChunkSize = 32 << 10
+
+// WrapStream copies src to dst as one wrapped block, escaping markers as NewWriter does, so
+// a complete copy writes WrapContentSafe of src's content. ctx is checked before every chunk
+// of up to 32 KiB; once it is done WrapStream stops and returns ctx.Err() without writing
+// the end marker, so a cancelled copy can't pass for a whole block. A Read that is already
+// blocked isn't interrupted; close src to unblock it. An error from src or dst is returned
+// as is, also without the end marker.
+func WrapStream(ctx context.Context, dst io.Writer, src io.Reader, source string) error {
+	w := NewWriter(dst, source)
+	buf := make([]byte, streamChunkSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := src.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return w.Close()
+}
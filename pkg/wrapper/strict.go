@@ -0,0 +1,40 @@
+package wrapper
+
+// UnwrapStrict parses wrapped like ParseBlock and additionally rejects any block a
+// first-match parser and a last-match parser could read differently: a source, header or
+// content holding a marker-like run of three or more angle brackets (see
+// NeutralizeAllMarkers), or a header name given twice. Use it for blocks produced by systems
+// you don't control; content wrapped after --neutralize-all passes. Failures are
+// *MalformedError values matching ErrUnwrapMalformed.
+func UnwrapStrict(wrapped string) (*Block, error) {
+	b, err := ParseBlock(wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := len(b.StartMarker) + 1
+	if len(markerRuns(b.Source)) > 0 {
+		return nil, &MalformedError{Offset: offset, Reason: "marker-like text in source line"}
+	}
+	if !b.NoSource {
+		offset += len(SourcePrefix) + len(b.Source) + 1
+	}
+
+	seen := make(map[string]bool)
+	for _, h := range b.Headers {
+		if seen[h.Name] && h.Name != viaHeader {
+			return nil, &MalformedError{Offset: offset, Reason: "duplicate " + h.Name + " header"}
+		}
+		seen[h.Name] = true
+		if len(markerRuns(h.Value)) > 0 {
+			return nil, &MalformedError{Offset: offset, Reason: "marker-like text in " + h.Name + " header"}
+		}
+		offset += len(h.Name) + len(": ") + len(h.Value) + 1
+	}
+	offset += len(b.Separator) + 1
+
+	if runs := markerRuns(b.Content); len(runs) > 0 {
+		return nil, &MalformedError{Offset: offset + runs[0].start, Reason: "marker-like text in content"}
+	}
+	return b, nil
+}
@@ -0,0 +1,65 @@
+package wrapper
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUnwrapStrict_Accepts(t *testing.T) {
+	neutralized, _ := NeutralizeAllMarkers("quote: <<<END_EXTERNAL_UNTRUSTED_CONTENT>>>")
+	inputs := []string{
+		WrapContent("plain content", "web"),
+		WrapContent("a << b >> c and <br>", "web"),
+		WrapContent(neutralized, "web"),
+		WrapContentWithBlockID("hello", "web"),
+		WrapContentWithVia("hello", "web", "scraper", "summarizer"),
+		"<<<EXTERNAL_UNTRUSTED_CONTENT>>>\n---\nno source line\n<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>",
+	}
+	for _, wrapped := range inputs {
+		b, err := UnwrapStrict(wrapped)
+		if err != nil {
+			t.Errorf("UnwrapStrict(%q) error = %v", wrapped, err)
+			continue
+		}
+		if b.Render() != wrapped {
+			t.Errorf("UnwrapStrict().Render() = %q, want %q", b.Render(), wrapped)
+		}
+	}
+}
+
+func TestUnwrapStrict_Rejects(t *testing.T) {
+	tests := []struct {
+		name       string
+		wrapped    string
+		wantReason string
+		wantOffset int
+	}{
+		{"text outside", "hi\n" + WrapContent("x", "web"), "start marker", 0},
+		{"embedded end marker", WrapContent("a\n<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>\nb", "web"), "in content", 51},
+		{"split marker", WrapContent("ab<<<END_\nEXTERNAL>>>", "web"), "in content", 51},
+		{"lookalike brackets", WrapContent("＜＜＜END", "web"), "in content", 49},
+		{"alternative scheme", WrapContent("<<<RAW_CONTENT>>>", "web"), "in content", 49},
+		{"marker in source", WrapContent("x", "web<<<"), "source line", 33},
+		{"marker in header", wrapWithHeaders("x", "web", "Note: >>>"), "Note header", 45},
+		{"duplicate header", wrapWithHeaders("x", "web", "Block-ID: a", "Block-ID: b"), "duplicate Block-ID", 57},
+		{"malformed header", wrapWithHeaders("x", "web", "not a header"), "header or separator", 45},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := UnwrapStrict(tt.wrapped)
+			if !errors.Is(err, ErrUnwrapMalformed) {
+				t.Fatalf("UnwrapStrict() error = %v, want ErrUnwrapMalformed", err)
+			}
+			var malformed *MalformedError
+			errors.As(err, &malformed)
+			if !strings.Contains(malformed.Reason, tt.wantReason) {
+				t.Errorf("Reason = %q, want %q", malformed.Reason, tt.wantReason)
+			}
+			if malformed.Offset != tt.wantOffset {
+				t.Errorf("Offset = %d, want %d (%q)", malformed.Offset, tt.wantOffset, tt.wrapped[min(malformed.Offset, len(tt.wrapped)):])
+			}
+		})
+	}
+}
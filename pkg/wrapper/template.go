@@ -0,0 +1,90 @@
+package wrapper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TemplateDialect names a templating syntax whose delimiters EscapeTemplating neutralizes
+type TemplateDialect string
+
+// Supported template dialects
+const (
+	// TemplateGo escapes {{ and }} for text/template and html/template as {{"{{"}} and {{"}}"}}
+	TemplateGo TemplateDialect = "go"
+	// TemplateJinja escapes {{ }} {% %} {# #} as Jinja string expressions such as {{ '{{' }}
+	TemplateJinja TemplateDialect = "jinja"
+	// TemplateShell backslash-escapes \, $ and ` so ${...}, $(...) and `...` stay literal
+	// inside an unquoted heredoc
+	TemplateShell TemplateDialect = "shell"
+)
+
+// templateEscapers and templateUnescapers map each dialect to its escaping and its inverse
+var (
+	templateEscapers = map[TemplateDialect]*strings.Replacer{
+		TemplateGo: strings.NewReplacer(
+			"{{", `{{"{{"}}`,
+			"}}", `{{"}}"}}`,
+		),
+		TemplateJinja: strings.NewReplacer(
+			"{{", "{{ '{{' }}",
+			"}}", "{{ '}}' }}",
+			"{%", "{{ '{%' }}",
+			"%}", "{{ '%}' }}",
+			"{#", "{{ '{#' }}",
+			"#}", "{{ '#}' }}",
+		),
+		TemplateShell: strings.NewReplacer(
+			`\`, `\\`,
+			"$", `\$`,
+			"`", "\\`",
+		),
+	}
+	templateUnescapers = map[TemplateDialect]*strings.Replacer{
+		TemplateGo: strings.NewReplacer(
+			`{{"{{"}}`, "{{",
+			`{{"}}"}}`, "}}",
+		),
+		TemplateJinja: strings.NewReplacer(
+			"{{ '{{' }}", "{{",
+			"{{ '}}' }}", "}}",
+			"{{ '{%' }}", "{%",
+			"{{ '%}' }}", "%}",
+			"{{ '{#' }}", "{#",
+			"{{ '#}' }}", "#}",
+		),
+		TemplateShell: strings.NewReplacer(
+			`\\`, `\`,
+			`\$`, "$",
+			"\\`", "`",
+		),
+	}
+)
+
+// ParseTemplateDialect returns the dialect called name
+func ParseTemplateDialect(name string) (TemplateDialect, error) {
+	d := TemplateDialect(name)
+	if _, ok := templateEscapers[d]; !ok {
+		return "", fmt.Errorf("unknown template dialect %q: want go, jinja, or shell", name)
+	}
+	return d, nil
+}
+
+// EscapeTemplating rewrites the template delimiters of dialect in content so a templater
+// rendering the wrapped output reproduces content literally. Content with no delimiters is
+// unchanged, and an unknown dialect leaves content unchanged.
+func EscapeTemplating(content string, dialect TemplateDialect) string {
+	if r, ok := templateEscapers[dialect]; ok {
+		return r.Replace(content)
+	}
+	return content
+}
+
+// UnescapeTemplating reverses EscapeTemplating for consumers that read the output without
+// rendering it as a template
+func UnescapeTemplating(content string, dialect TemplateDialect) string {
+	if r, ok := templateUnescapers[dialect]; ok {
+		return r.Replace(content)
+	}
+	return content
+}
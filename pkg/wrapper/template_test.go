@@ -0,0 +1,99 @@
+package wrapper
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+// templateSamples cover delimiters, near-misses and text that looks like escaped output
+var templateSamples = []string{
+	"",
+	"no delimiters",
+	"{{.Secret}}",
+	"{{ range . }}{{ end }}",
+	"{{{ triple }}}",
+	"{ single } }",
+	`{{"{{"}}`,
+	"{% if x %}{# note #}{% endif %}",
+	"{{ '{{' }}",
+	"${HOME} $(id) `id` \\$ \\\\",
+	"$",
+	"\\",
+}
+
+func TestEscapeTemplating_RoundTrip(t *testing.T) {
+	for _, dialect := range []TemplateDialect{TemplateGo, TemplateJinja, TemplateShell} {
+		for _, content := range templateSamples {
+			escaped := EscapeTemplating(content, dialect)
+			if got := UnescapeTemplating(escaped, dialect); got != content {
+				t.Errorf("%s: Unescape(Escape(%q)) = %q (escaped %q)", dialect, content, got, escaped)
+			}
+		}
+	}
+}
+
+func TestEscapeTemplating_GoTemplate(t *testing.T) {
+	for _, content := range templateSamples {
+		wrapped := WrapContent(EscapeTemplating(content, TemplateGo), "web")
+
+		tmpl, err := template.New("t").Parse(wrapped)
+		if err != nil {
+			t.Errorf("Parse of escaped %q failed: %v", content, err)
+			continue
+		}
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, map[string]string{"Secret": "leaked"}); err != nil {
+			t.Errorf("Execute of escaped %q failed: %v", content, err)
+			continue
+		}
+		if want := WrapContent(content, "web"); out.String() != want {
+			t.Errorf("Rendered %q, want %q", out.String(), want)
+		}
+	}
+}
+
+func TestEscapeTemplating_ShellHeredoc(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not installed")
+	}
+	for _, content := range templateSamples {
+		if content == "" {
+			continue
+		}
+		script := "cat <<EOF\n" + EscapeTemplating(content, TemplateShell) + "\nEOF\n"
+		out, err := exec.Command(sh, "-c", script).Output()
+		if err != nil {
+			t.Errorf("sh failed for %q: %v", content, err)
+			continue
+		}
+		if got := strings.TrimSuffix(string(out), "\n"); got != content {
+			t.Errorf("Heredoc rendered %q, want %q", got, content)
+		}
+	}
+}
+
+func TestEscapeTemplating_Jinja(t *testing.T) {
+	got := EscapeTemplating("{{ x }} {% y %} {# z #}", TemplateJinja)
+	want := "{{ '{{' }} x {{ '}}' }} {{ '{%' }} y {{ '%}' }} {{ '{#' }} z {{ '#}' }}"
+	if got != want {
+		t.Errorf("EscapeTemplating(jinja) = %q, want %q", got, want)
+	}
+}
+
+func TestParseTemplateDialect(t *testing.T) {
+	for _, name := range []string{"go", "jinja", "shell"} {
+		if d, err := ParseTemplateDialect(name); err != nil || string(d) != name {
+			t.Errorf("ParseTemplateDialect(%q) = %q, %v", name, d, err)
+		}
+	}
+	if _, err := ParseTemplateDialect("mustache"); err == nil {
+		t.Error("Expected error for unknown dialect")
+	}
+	if got := EscapeTemplating("{{x}}", "mustache"); got != "{{x}}" {
+		t.Errorf("Unknown dialect changed content: %q", got)
+	}
+}
@@ -0,0 +1,20 @@
+package wrapper
+
+import "time"
+
+// wrappedAtHeader names the audit header added by WrapContentWithTimestamp
+const wrappedAtHeader = "Wrapped-At"
+
+// WrapContentWithTimestamp wraps content with a "Wrapped-At: <RFC 3339 time>" header after
+// the source line, recording the current time in UTC. Wrap with WithTimestamp(time.Time{})
+// and WithClock does the same with a clock of the caller's choosing.
+func WrapContentWithTimestamp(content, source string) string {
+	return Wrap(content, source, WithTimestamp(time.Time{}))
+}
+
+// WrapContentAt is WrapContentWithTimestamp with the time given, for callers that record
+// when content was fetched rather than when it was wrapped. The time is converted to UTC
+// so the header reads the same on every host.
+func WrapContentAt(content, source string, t time.Time) string {
+	return Wrap(content, source, WithTimestamp(t))
+}
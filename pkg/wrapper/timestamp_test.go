@@ -0,0 +1,59 @@
+package wrapper
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrapContentWithTimestamp(t *testing.T) {
+	before := time.Now().UTC().Truncate(time.Second)
+	wrapped := WrapContentWithTimestamp("data", "web")
+	after := time.Now().UTC()
+
+	b, err := ParseBlock(wrapped)
+	if err != nil {
+		t.Fatalf("ParseBlock() error = %v", err)
+	}
+	v, ok := b.Header("Wrapped-At")
+	if !ok {
+		t.Fatalf("WrapContentWithTimestamp() = %q, want a Wrapped-At header", wrapped)
+	}
+	stamp, err := time.Parse(time.RFC3339, v)
+	if err != nil || stamp.Before(before) || stamp.After(after) {
+		t.Errorf("Wrapped-At = %q, want a time between %v and %v", v, before, after)
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	t.Parallel()
+	clock := func() time.Time { return time.Date(2024, 3, 9, 14, 5, 7, 123456789, time.UTC) }
+
+	wrapped := Wrap("data", "web", WithClock(clock), WithTimestamp(time.Time{}))
+	want := "<<<EXTERNAL_UNTRUSTED_CONTENT>>>\nSource: web\nWrapped-At: 2024-03-09T14:05:07Z\n---\ndata\n<<<END_EXTERNAL_UNTRUSTED_CONTENT>>>"
+	if wrapped != want {
+		t.Errorf("Wrap(WithClock) = %q, want %q", wrapped, want)
+	}
+
+	// An explicit time wins over the clock, and the clock alone adds no header
+	if got := Wrap("data", "web", WithClock(clock), WithTimestamp(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))); !strings.Contains(got, "\nWrapped-At: 2020-01-02T03:04:05Z\n") {
+		t.Errorf("Wrap(WithClock, WithTimestamp(t)) = %q, want the given time", got)
+	}
+	if got := Wrap("data", "web", WithClock(clock)); got != WrapContent("data", "web") {
+		t.Errorf("Wrap(WithClock) without WithTimestamp = %q, want plain WrapContent", got)
+	}
+}
+
+func TestWrapContentAt_UTC(t *testing.T) {
+	zone := time.FixedZone("UTC+9", 9*60*60)
+	wrapped := WrapContentAt("data", "web", time.Date(2024, 3, 10, 1, 0, 0, 0, zone))
+	if !strings.Contains(wrapped, "\nWrapped-At: 2024-03-09T16:00:00Z\n") {
+		t.Errorf("WrapContentAt() = %q, want the time in UTC", wrapped)
+	}
+}
+
+func TestWrapContent_NoTimestamp(t *testing.T) {
+	if strings.Contains(WrapContent("data", "web"), "Wrapped-At") {
+		t.Error("WrapContent must not add a timestamp")
+	}
+}
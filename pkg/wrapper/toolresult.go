@@ -0,0 +1,32 @@
+package wrapper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// toolResultMessage is the OpenAI chat completions message answering a tool call
+type toolResultMessage struct {
+	Role       string `json:"role"`
+	ToolCallID string `json:"tool_call_id"`
+	Content    string `json:"content"`
+}
+
+// WrapAsToolResult wraps content and returns it as an OpenAI tool result message,
+// {"role":"tool","tool_call_id":...,"content":...}, with the wrapped text as the content.
+// Markers are left unescaped by the JSON encoder so the message stays readable; decoding
+// the content gives back exactly WrapContent(content, source).
+func WrapAsToolResult(content, toolCallID, source string) (string, error) {
+	if toolCallID == "" {
+		return "", ErrMissingToolCallID
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	msg := toolResultMessage{Role: "tool", ToolCallID: toolCallID, Content: WrapContent(content, source)}
+	if err := enc.Encode(msg); err != nil {
+		return "", fmt.Errorf("encoding tool result: %w", err)
+	}
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
+}
@@ -0,0 +1,165 @@
+package wrapper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Transform rewrites content before wrapping and reports how many changes it made
+type Transform func(string) (string, int)
+
+// Pipeline applies transforms in order, each seeing the previous one's output
+type Pipeline []Transform
+
+// Apply runs the pipeline over content, returning the result and each transform's change count
+func (p Pipeline) Apply(content string) (string, []int) {
+	counts := make([]int, len(p))
+	for i, transform := range p {
+		content, counts[i] = transform(content)
+	}
+	return content, counts
+}
+
+// transforms are the named transforms accepted by ParsePipeline
+var transforms = map[string]Transform{
+	"strip-trailing-cr":    stripTrailingCR,
+	"trim-ws":              trimTrailingWhitespace,
+	"collapse-blank-lines": collapseBlankLines,
+	"neutralize-all":       NeutralizeAllMarkers,
+	"strip-invisible":      StripInvisible,
+	"redact":               Redact,
+	"strip-ansi":           stripTerminalEscapes,
+}
+
+// argTransforms are the named transforms that take an argument after a colon, as in
+// "normalize:nfc". args lists the accepted arguments for help text, and build returns the
+// transform for one of them or an error.
+var argTransforms = map[string]struct {
+	args  string
+	build func(arg string) (Transform, error)
+}{
+	"normalize": {"nfc|nfkc", normalizeTransform},
+}
+
+// TransformNames lists the names accepted by ParsePipeline, sorted. A transform taking an
+// argument is listed with its accepted arguments, as "normalize:nfc|nfkc".
+func TransformNames() []string {
+	names := make([]string, 0, len(transforms)+len(argTransforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+	for name, t := range argTransforms {
+		names = append(names, name+":"+t.args)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParsePipeline builds a pipeline from a comma-separated list of transform names such as
+// "trim-ws,collapse-blank-lines". It also returns the names in order, for reporting counts.
+// A name may carry an argument after a colon, as in "normalize:nfkc"; only the transforms
+// that take one accept it, and they require it.
+func ParsePipeline(spec string) (Pipeline, []string, error) {
+	var pipeline Pipeline
+	var names []string
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		name, arg, hasArg := strings.Cut(field, ":")
+		transform, ok := transforms[name]
+		if at, takesArg := argTransforms[name]; takesArg {
+			if !hasArg {
+				return nil, nil, fmt.Errorf("transform %q needs an argument (%s:%s)", name, name, at.args)
+			}
+			var err error
+			if transform, err = at.build(arg); err != nil {
+				return nil, nil, err
+			}
+		} else if !ok {
+			return nil, nil, fmt.Errorf("%w %q (available: %s)", ErrUnknownTransform, name, strings.Join(TransformNames(), ", "))
+		} else if hasArg {
+			return nil, nil, fmt.Errorf("transform %q takes no argument, got %q", name, arg)
+		}
+		pipeline = append(pipeline, transform)
+		names = append(names, field)
+	}
+	return pipeline, names, nil
+}
+
+// normalizeTransform returns the normalize transform for arg, "nfc" or "nfkc". It counts
+// the lines the normalization changed.
+func normalizeTransform(arg string) (Transform, error) {
+	var form NormalizationForm
+	switch arg {
+	case "nfc":
+		form = NFC
+	case "nfkc":
+		form = NFKC
+	default:
+		return nil, fmt.Errorf("transform \"normalize\": unknown normalization form %q (available: nfc, nfkc)", arg)
+	}
+	return func(content string) (string, int) {
+		lines := strings.Split(content, "\n")
+		changed := 0
+		for i, line := range lines {
+			if normalized := form.normalize(line); normalized != line {
+				lines[i] = normalized
+				changed++
+			}
+		}
+		return strings.Join(lines, "\n"), changed
+	}, nil
+}
+
+// stripTrailingCR is TrimTrailingCR counting the carriage returns removed
+func stripTrailingCR(content string) (string, int) {
+	trimmed := TrimTrailingCR(content)
+	return trimmed, len(content) - len(trimmed)
+}
+
+// trimTrailingWhitespace removes spaces and tabs at the end of each line, counting the lines changed
+func trimTrailingWhitespace(content string) (string, int) {
+	lines := strings.Split(content, "\n")
+	changed := 0
+	for i, line := range lines {
+		// Keep a CR line ending intact while trimming the blanks before it
+		body, cr := strings.CutSuffix(line, "\r")
+		trimmed := strings.TrimRight(body, " \t")
+		if len(trimmed) != len(body) {
+			changed++
+			if cr {
+				trimmed += "\r"
+			}
+			lines[i] = trimmed
+		}
+	}
+	return strings.Join(lines, "\n"), changed
+}
+
+// collapseBlankLines reduces each run of blank lines to a single blank line, counting the
+// lines removed. Lines holding only whitespace count as blank.
+func collapseBlankLines(content string) (string, int) {
+	// A final newline terminates the last line rather than starting a blank one
+	body, terminated := strings.CutSuffix(content, "\n")
+	lines := strings.Split(body, "\n")
+	kept := lines[:0]
+	removed := 0
+	prevBlank := false
+	for _, line := range lines {
+		blank := strings.TrimSpace(line) == ""
+		if blank && prevBlank {
+			removed++
+			continue
+		}
+		kept = append(kept, line)
+		prevBlank = blank
+	}
+	collapsed := strings.Join(kept, "\n")
+	if terminated {
+		collapsed += "\n"
+	}
+	return collapsed, removed
+}
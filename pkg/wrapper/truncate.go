@@ -0,0 +1,57 @@
+package wrapper
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// partialMarkerPattern matches a cut-off marker at the end of text: a whole marker name
+// whose nonce or closing ">>>" is missing. Cut-off names are found by isPartialMarkerName.
+var partialMarkerPattern = regexp.MustCompile(`(?i)<<<(?:END_)?EXTERNAL_UNTRUSTED_CONTENT(?::[^<>\n]*)?>{0,2}$`)
+
+// WrapTruncated wraps at most maxBytes of content, followed by a visible notice such as
+// "…[truncated 120 bytes]" inside the block when anything was cut. The cut never splits a
+// UTF-8 rune and never leaves the start of a marker at the end of the kept text, so it can
+// fall a few bytes short of maxBytes; the notice counts every byte removed. Content that
+// fits is wrapped unchanged and truncated is false. A negative maxBytes counts as zero.
+func WrapTruncated(content, source string, maxBytes int) (wrapped string, truncated bool) {
+	if len(content) <= maxBytes {
+		return WrapContent(content, source), false
+	}
+
+	kept := content[:truncationPoint(content, maxBytes)]
+	notice := fmt.Sprintf("…[truncated %d bytes]", len(content)-len(kept))
+	return WrapContent(kept+notice, source), true
+}
+
+// truncationPoint returns where to cut content so that at most maxBytes are kept
+func truncationPoint(content string, maxBytes int) int {
+	cut := max(maxBytes, 0)
+	// Back up to the start of a rune, at most utf8.UTFMax-1 bytes so that a run of stray
+	// continuation bytes in invalid UTF-8 can't pull the cut further
+	for i := 0; i < utf8.UTFMax-1 && cut > 0 && !utf8.RuneStart(content[cut]); i++ {
+		cut--
+	}
+
+	kept := content[:cut]
+	if loc := partialMarkerPattern.FindStringIndex(kept); loc != nil {
+		return loc[0]
+	}
+	if i := strings.LastIndex(kept, "<<<"); i >= 0 && isPartialMarkerName(kept[i+3:]) {
+		return i
+	}
+	return cut
+}
+
+// isPartialMarkerName reports whether s, ignoring ASCII case, is the start of a marker
+// name, including the empty start
+func isPartialMarkerName(s string) bool {
+	for _, name := range markerNames {
+		if len(s) < len(name) && strings.EqualFold(s, name[:len(s)]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package wrapper
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// truncatedContent parses a WrapTruncated block and returns the kept content and notice
+func truncatedContent(t *testing.T, wrapped string) (kept, notice string) {
+	t.Helper()
+	b, err := ParseBlock(wrapped)
+	if err != nil {
+		t.Fatalf("ParseBlock() error = %v", err)
+	}
+	i := strings.LastIndex(b.Content, "…[truncated ")
+	if i < 0 {
+		t.Fatalf("no truncation notice in %q", b.Content)
+	}
+	return b.Content[:i], b.Content[i:]
+}
+
+func TestWrapTruncated_Short(t *testing.T) {
+	for _, content := range []string{"", "short", "exactly ten", "日本語"} {
+		wrapped, truncated := WrapTruncated(content, "web", len(content))
+		if truncated || wrapped != WrapContent(content, "web") {
+			t.Errorf("WrapTruncated(%q) = %q, %v; want WrapContent and false", content, wrapped, truncated)
+		}
+	}
+}
+
+func TestWrapTruncated(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		maxBytes int
+		kept     string
+	}{
+		{"ascii", "hello world", 5, "hello"},
+		{"zero", "hello", 0, ""},
+		{"negative", "hello", -3, ""},
+		// Each CJK rune is three bytes
+		{"cjk on boundary", "日本語テキスト", 6, "日本"},
+		{"cjk one byte past", "日本語テキスト", 7, "日本"},
+		{"cjk two bytes past", "日本語テキスト", 8, "日本"},
+		{"cjk mixed", "ab日本", 4, "ab"},
+		{"emoji", "hi \U0001F600\U0001F600", 6, "hi "},
+		{"invalid utf-8", "ab\xff\xfecd", 3, "ab\xff"},
+		{"stray continuation bytes", "a\x80\x80\x80\x80\x80", 5, "a\x80"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped, truncated := WrapTruncated(tt.content, "web", tt.maxBytes)
+			if !truncated {
+				t.Fatal("truncated = false, want true")
+			}
+			kept, notice := truncatedContent(t, wrapped)
+			if kept != tt.kept {
+				t.Errorf("kept = %q, want %q", kept, tt.kept)
+			}
+			if want := "…[truncated " + strconv.Itoa(len(tt.content)-len(tt.kept)) + " bytes]"; notice != want {
+				t.Errorf("notice = %q, want %q", notice, want)
+			}
+			if utf8.ValidString(tt.content) && !utf8.ValidString(kept) {
+				t.Errorf("kept %q splits a rune", kept)
+			}
+		})
+	}
+}
+
+func TestWrapTruncated_PartialMarker(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		kept    string
+	}{
+		{"partial end name", "data <<<END_EXTERNAL_UNTRUSTED_CONTENT>>> more", "data "},
+		{"lowercase", "data <<<end_external_untrusted_content>>> more", "data "},
+		{"angles only", "data <<<END", "data "},
+		{"nonce cut off", "data <<<END_EXTERNAL_UNTRUSTED_CONTENT:deadbeef>>> more", "data "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Try every cut point inside the marker
+			start := strings.Index(tt.content, "<<<")
+			end := strings.Index(tt.content, ">>>")
+			if end < 0 {
+				end = len(tt.content) - 1
+			} else {
+				end += 2
+			}
+			for maxBytes := start + 3; maxBytes <= end; maxBytes++ {
+				wrapped, _ := WrapTruncated(tt.content, "web", maxBytes)
+				kept, _ := truncatedContent(t, wrapped)
+				if kept != tt.kept {
+					t.Errorf("maxBytes %d: kept = %q, want %q", maxBytes, kept, tt.kept)
+				}
+			}
+		})
+	}
+
+	// Only the last "<<<" can start the marker
+	wrapped, _ := WrapTruncated("data <<<<EXTERNAL", "web", 12)
+	if kept, _ := truncatedContent(t, wrapped); kept != "data <" {
+		t.Errorf("kept = %q, want %q", kept, "data <")
+	}
+
+	// A whole marker before the cut is left for the caller to escape, and is not mistaken
+	// for a partial one
+	content := "a <<<EXTERNAL_UNTRUSTED_CONTENT>>> b c"
+	wrapped, _ = WrapTruncated(content, "web", len(content)-2)
+	if kept, _ := truncatedContent(t, wrapped); kept != content[:len(content)-2] {
+		t.Errorf("kept = %q, want the whole marker kept", kept)
+	}
+}
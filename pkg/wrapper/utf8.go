@@ -0,0 +1,28 @@
+package wrapper
+
+import "unicode/utf8"
+
+// WrapContentUTF8 wraps content like WrapContent but refuses content that is not valid
+// UTF-8, which some LLM APIs reject, so callers can sanitize it upstream. The error is an
+// *InvalidUTF8Error giving the offset of the first invalid byte. The source is not checked.
+func WrapContentUTF8(content, source string) (string, error) {
+	if i := invalidUTF8Offset(content); i >= 0 {
+		return "", &InvalidUTF8Error{Offset: i}
+	}
+	return WrapContent(content, source), nil
+}
+
+// invalidUTF8Offset returns the offset of the first invalid UTF-8 sequence in s, or -1
+func invalidUTF8Offset(s string) int {
+	if utf8.ValidString(s) {
+		return -1
+	}
+	for i, r := range s {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,56 @@
+package wrapper
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWrapContentUTF8(t *testing.T) {
+	allBytes := make([]byte, 256)
+	for i := range allBytes {
+		allBytes[i] = byte(i)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		offset  int // -1 for valid content
+	}{
+		{"ascii", "hello world", -1},
+		{"empty", "", -1},
+		{"multibyte", "日本語 \U0001F600", -1},
+		{"replacement character", "valid \ufffd is fine", -1},
+		{"all byte values", string(allBytes), 0x80},
+		{"high bytes", string([]byte{0xFF, 0xFE, 0xFD, 0xFC, 0xFB, 0xFA}), 0},
+		{"after multibyte", "日本\xff", 6},
+		{"truncated rune", "ok \xe6\x97", 3},
+		{"overlong encoding", "a\xc0\xafb", 1},
+		{"surrogate half", "a\xed\xa0\x80", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WrapContentUTF8(tt.content, "web")
+			if tt.offset < 0 {
+				if err != nil {
+					t.Fatalf("WrapContentUTF8() error = %v", err)
+				}
+				if got != WrapContent(tt.content, "web") {
+					t.Errorf("WrapContentUTF8() = %q, want WrapContent output", got)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrInvalidUTF8) {
+				t.Fatalf("WrapContentUTF8() error = %v, want ErrInvalidUTF8", err)
+			}
+			var uerr *InvalidUTF8Error
+			if !errors.As(err, &uerr) || uerr.Offset != tt.offset {
+				t.Errorf("error = %#v, want offset %d", err, tt.offset)
+			}
+			if got != "" {
+				t.Errorf("WrapContentUTF8() = %q, want no output on error", got)
+			}
+		})
+	}
+}
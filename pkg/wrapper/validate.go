@@ -0,0 +1,112 @@
+package wrapper
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// IssueKind names a problem Validate finds in content
+type IssueKind string
+
+// Issue kinds reported by Validate
+const (
+	ContainsStartMarker  IssueKind = "contains-start-marker"
+	ContainsEndMarker    IssueKind = "contains-end-marker"
+	ContainsBidiOverride IssueKind = "contains-bidi-override"
+	ContainsNullByte     IssueKind = "contains-null-byte"
+)
+
+// Issue is something in content that could compromise the block it is wrapped in
+type Issue struct {
+	Kind   IssueKind
+	Offset int // byte offset in the content
+}
+
+// contentMarkerPattern matches either marker anywhere in a line, with or without a nonce
+var contentMarkerPattern = regexp.MustCompile(`<<<(?:END_)?EXTERNAL_UNTRUSTED_CONTENT(?::[^<>\n]*)?>>>`)
+
+// Validate reports what in content would compromise a block if it were wrapped verbatim:
+// the start or end marker, nonce-suffixed or not, anywhere including mid-line; a Unicode
+// bidi embedding, override or isolate control, which can reorder how the block reads; and
+// NUL bytes, which truncate content for C-string consumers. Every occurrence is reported, in
+// offset order. Content is never modified; a nil result means it is safe to wrap as is.
+func Validate(content string) []Issue {
+	var issues []Issue
+	for _, loc := range contentMarkerPattern.FindAllStringIndex(content, -1) {
+		kind := ContainsStartMarker
+		if strings.HasPrefix(content[loc[0]:], "<<<END_") {
+			kind = ContainsEndMarker
+		}
+		issues = append(issues, Issue{Kind: kind, Offset: loc[0]})
+	}
+	for i, r := range content {
+		switch {
+		case r == 0:
+			issues = append(issues, Issue{Kind: ContainsNullByte, Offset: i})
+		case isBidiControl(r):
+			issues = append(issues, Issue{Kind: ContainsBidiOverride, Offset: i})
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Offset < issues[j].Offset })
+	return issues
+}
+
+// BlockIssue is a boundary problem found by ValidateBlocks
+type BlockIssue struct {
+	Block  int // index of the block in the text, or -1 for a marker outside any block
+	Offset int // byte offset of the problem in the text
+	Reason string
+}
+
+// ValidateBlocks checks every block in an assembled prompt and returns the problems found,
+// in text order, or nil when all blocks are boundary-safe. Blocks are delimited the way a
+// first-match parser reads them: from a start marker line to the next matching end marker
+// line. Each block must then pass UnwrapStrict, so embedded markers, malformed or repeated
+// headers are reported at their offset. An end marker line outside any block and a block
+// that is never closed are reported too. Only the first problem inside each block is
+// reported.
+func ValidateBlocks(text string) []BlockIssue {
+	var issues []BlockIssue
+	block := 0
+	open := -1 // offset of the current block's start marker, or -1
+	var wantEnd string
+
+	for _, l := range splitLines(text) {
+		if open < 0 {
+			switch {
+			case isStartMarker(l.text):
+				open = l.offset
+				wantEnd = "<<<END_" + strings.TrimPrefix(l.text, "<<<")
+			case isEndMarker(l.text):
+				issues = append(issues, BlockIssue{Block: -1, Offset: l.offset, Reason: "end marker outside any block"})
+			}
+			continue
+		}
+		if l.text != wantEnd {
+			continue
+		}
+
+		_, err := UnwrapStrict(text[open : l.offset+len(l.text)])
+		var malformed *MalformedError
+		if errors.As(err, &malformed) {
+			issues = append(issues, BlockIssue{Block: block, Offset: open + malformed.Offset, Reason: malformed.Reason})
+		}
+		block++
+		open = -1
+	}
+
+	if open >= 0 {
+		issues = append(issues, BlockIssue{Block: block, Offset: open, Reason: "block has no matching end marker"})
+	}
+	return issues
+}
+
+// isEndMarker reports whether text is the end marker, with or without a nonce suffix
+func isEndMarker(text string) bool {
+	if text == EndMarker {
+		return true
+	}
+	return strings.HasPrefix(text, strings.TrimSuffix(EndMarker, ">>>")+":") && strings.HasSuffix(text, ">>>")
+}
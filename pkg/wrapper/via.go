@@ -0,0 +1,63 @@
+package wrapper
+
+import "strings"
+
+// viaHeader names the provenance hop headers, one per hop in order
+const viaHeader = "Via"
+
+// viaEscaper keeps each hop on its header line; backslashes are escaped first so the
+// escaping can be reversed exactly
+var viaEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+
+// WrapContentWithVia wraps content with one "Via: <hop>" header per provenance hop, in order
+// from the origin to the last relay, e.g. web, scraper, summarizer. Hops are operator metadata;
+// CR, LF and backslash are escaped so a hop can't add lines to the header.
+func WrapContentWithVia(content, source string, via ...string) string {
+	b := NewBlock(content, source)
+	b.AddVia(via...)
+	return b.Render()
+}
+
+// AddVia appends a Via header for each hop, escaped to a single line
+func (b *Block) AddVia(hops ...string) {
+	for _, hop := range hops {
+		b.Headers = append(b.Headers, Header{Name: viaHeader, Value: viaEscaper.Replace(hop)})
+	}
+}
+
+// Via returns the block's provenance chain in order, with hop escaping undone
+func (b *Block) Via() []string {
+	var hops []string
+	for _, h := range b.Headers {
+		if h.Name == viaHeader {
+			hops = append(hops, unescapeVia(h.Value))
+		}
+	}
+	return hops
+}
+
+// unescapeVia reverses viaEscaper; unknown escapes are kept as written
+func unescapeVia(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '\\':
+			b.WriteByte('\\')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteString(s[i : i+2])
+		}
+		i++
+	}
+	return b.String()
+}
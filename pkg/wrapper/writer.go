@@ -0,0 +1,171 @@
+package wrapper
+
+import "io"
+
+// escapingWriter is the io.WriteCloser returned by NewWriter and NewScrubbingWriter
+type escapingWriter struct {
+	dst    io.Writer
+	source string
+	bare   bool   // escape only, without the header and end marker
+	out    []byte // scratch buffer for one Write's output
+
+	started bool
+	closed  bool
+	err     error
+
+	angles int    // consecutive '<' just written
+	armed  bool   // "<<<" and any backslashes were just written, so a marker name may follow
+	hold   []byte // held-back prefix of a marker name, at most the longest name
+}
+
+// NewWriter returns a writer that streams a wrapped block to dst without buffering the
+// content: the header goes out with the first Write, content passes through as it arrives,
+// and Close writes the end marker. Marker names in the content are escaped as
+// EscapeMarkers does, even when split across Write calls, so the bytes written to dst equal
+// WrapContentSafe of the concatenated content. At most one marker name's worth of bytes is
+// held back between writes. Writes after Close fail with ErrFinished; a write error from dst
+// is sticky. The writer is not safe for concurrent use.
+func NewWriter(dst io.Writer, source string) io.WriteCloser {
+	return &escapingWriter{dst: dst, source: EscapeMarkers(source)}
+}
+
+// NewScrubbingWriter returns a writer that escapes marker names in everything written to
+// dst as EscapeMarkers does, even when a marker is split across Write calls, so no bare
+// marker can reach a trusted stream such as a log or a system prompt. It adds no markers of
+// its own. Close writes the few bytes held back in case they begin a marker name; it does
+// not close dst. Blocks from NewWriter carry real markers, so write them to dst directly
+// rather than through a scrubbing writer. The writer is not safe for concurrent use.
+func NewScrubbingWriter(dst io.Writer) io.WriteCloser {
+	return &escapingWriter{dst: dst, bare: true}
+}
+
+func (w *escapingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.closed {
+		return 0, ErrFinished
+	}
+	if err := w.start(); err != nil {
+		return 0, err
+	}
+
+	w.out = w.out[:0]
+	for _, c := range p {
+		w.escapeByte(c)
+	}
+	if _, w.err = w.dst.Write(w.out); w.err != nil {
+		return 0, w.err
+	}
+	return len(p), nil
+}
+
+// escapeByte appends c to w.out, inserting a backslash before each complete marker name
+// that follows "<<<" and optional backslashes. Putting the extra backslash after the run
+// rather than before it gives the same bytes as EscapeMarkers while letting the run stream.
+func (w *escapingWriter) escapeByte(c byte) {
+	if w.armed {
+		if len(w.hold) == 0 && c == '\\' {
+			w.out = append(w.out, c)
+			w.angles = 0
+			return
+		}
+		if isMarkerNamePrefix(w.hold, c) {
+			w.hold = append(w.hold, c)
+			w.angles = 0
+			if isMarkerName(w.hold) {
+				w.out = append(w.out, '\\')
+				w.flushHold()
+			}
+			return
+		}
+		w.flushHold()
+	}
+
+	w.out = append(w.out, c)
+	if c == '<' {
+		w.angles++
+	} else {
+		w.angles = 0
+	}
+	w.armed = w.angles >= 3
+}
+
+// flushHold writes out the held name prefix unchanged and disarms the writer
+func (w *escapingWriter) flushHold() {
+	w.out = append(w.out, w.hold...)
+	w.hold = w.hold[:0]
+	w.armed = false
+}
+
+// Close writes any held-back bytes and the end marker, emitting a complete empty block if
+// nothing was written. A scrubbing writer writes only the held-back bytes. Closing again is
+// a no-op.
+func (w *escapingWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.closed {
+		return nil
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+	w.closed = true
+	w.out = append(w.out[:0], w.hold...)
+	if !w.bare {
+		w.out = append(w.out, '\n')
+		w.out = append(w.out, EndMarker...)
+	}
+	_, w.err = w.dst.Write(w.out)
+	return w.err
+}
+
+func (w *escapingWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	if w.bare {
+		return nil
+	}
+	_, w.err = io.WriteString(w.dst, StartMarker+"\n"+SourcePrefix+w.source+"\n"+Separator+"\n")
+	return w.err
+}
+
+// isMarkerNamePrefix reports whether prefix+c starts a marker name, ignoring ASCII case
+func isMarkerNamePrefix(prefix []byte, c byte) bool {
+	n := len(prefix)
+	for _, name := range markerNames {
+		if n < len(name) && upperASCII(c) == name[n] && equalFoldASCII(prefix, name[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMarkerName reports whether b is a whole marker name, ignoring ASCII case
+func isMarkerName(b []byte) bool {
+	for _, name := range markerNames {
+		if len(b) == len(name) && equalFoldASCII(b, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalFoldASCII(b []byte, s string) bool {
+	for i := range b {
+		if upperASCII(b[i]) != s[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func upperASCII(c byte) byte {
+	if 'a' <= c && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
@@ -0,0 +1,99 @@
+package wrapper
+
+import (
+	"encoding/base64"
+	"strings"
+	"unicode/utf8"
+)
+
+// cdataMinEscapes is how many '&', '<' and '>' characters content needs before WrapXML
+// switches from entity escaping to a CDATA section
+const cdataMinEscapes = 8
+
+// WrapXML returns content as an XML element for prompt templates built from XML:
+// <untrusted source="...">content</untrusted>. The element stands in for the markers, and
+// escaping keeps the content from closing it early.
+//
+// Content is entity-escaped (&amp; &lt; &gt;, with carriage returns as &#13; so parsers
+// don't fold them into newlines). Markup-heavy content, with cdataMinEscapes or more
+// characters to escape, goes in a CDATA section instead so it reads as written; any "]]>"
+// in it is split across two sections. CDATA can't hold a carriage return, so content with
+// one is always escaped.
+//
+// XML 1.0 can't represent NUL and most other C0 controls, U+FFFE, U+FFFF, or invalid UTF-8,
+// not even as character references. Content containing any of them is base64-encoded and
+// the element gains encoding="base64". In the source attribute such characters become U+FFFD.
+func WrapXML(content, source string) string {
+	var b strings.Builder
+	b.WriteString(`<untrusted source="`)
+	b.WriteString(escapeXMLAttr(source))
+	b.WriteByte('"')
+
+	switch {
+	case !isXMLText(content):
+		b.WriteString(` encoding="base64">`)
+		b.WriteString(base64.StdEncoding.EncodeToString([]byte(content)))
+	case strings.Count(content, "&")+strings.Count(content, "<")+strings.Count(content, ">") >= cdataMinEscapes &&
+		!strings.Contains(content, "\r"):
+		b.WriteString("><![CDATA[")
+		b.WriteString(strings.ReplaceAll(content, "]]>", "]]]]><![CDATA[>"))
+		b.WriteString("]]>")
+	default:
+		b.WriteByte('>')
+		b.WriteString(xmlTextReplacer.Replace(content))
+	}
+
+	b.WriteString("</untrusted>")
+	return b.String()
+}
+
+var xmlTextReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#13;")
+
+// xmlAttrReplacer also escapes the whitespace that attribute normalization would turn into spaces
+var xmlAttrReplacer = strings.NewReplacer(
+	"&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;",
+	"\t", "&#9;", "\n", "&#10;", "\r", "&#13;",
+)
+
+func escapeXMLAttr(s string) string {
+	if !isXMLText(s) {
+		s = strings.Map(func(r rune) rune {
+			if !isXMLChar(r) {
+				return utf8.RuneError
+			}
+			return r
+		}, strings.ToValidUTF8(s, string(utf8.RuneError)))
+	}
+	return xmlAttrReplacer.Replace(s)
+}
+
+// isXMLText reports whether s is valid UTF-8 made only of characters XML 1.0 allows
+func isXMLText(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if !isXMLChar(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isXMLChar reports whether r matches the Char production of XML 1.0
+func isXMLChar(r rune) bool {
+	switch {
+	case r == '\t' || r == '\n' || r == '\r':
+		return true
+	case r < 0x20:
+		return false
+	case r <= 0xD7FF:
+		return true
+	case r < 0xE000:
+		return false
+	case r <= 0xFFFD:
+		return true
+	default:
+		return r >= 0x10000 && r <= utf8.MaxRune
+	}
+}